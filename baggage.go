@@ -0,0 +1,233 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+const (
+	// BaggagePrefix is the prefix of the remote info keys that carry baggage.
+	BaggagePrefix = "baggage-"
+	// BaggageTagPrefix is the prefix of the span tags baggage is exported as.
+	BaggageTagPrefix = "baggage."
+
+	// DefaultBaggageMaxItems is the default number of baggage items a trace
+	// can carry.
+	DefaultBaggageMaxItems = 16
+	// DefaultBaggageMaxValueLength is the default length baggage values are
+	// truncated to.
+	DefaultBaggageMaxValueLength = 256
+)
+
+// BaggageOptions restricts the baggage that is accepted by the traces of a
+// registry, whether it's set locally or received from another process.
+type BaggageOptions struct {
+	// AllowedKeys lists the accepted baggage keys. If empty, all keys are
+	// accepted.
+	AllowedKeys []string
+	// MaxItems limits the number of baggage items of a trace. Items beyond
+	// the limit are dropped. Defaults to DefaultBaggageMaxItems.
+	MaxItems int
+	// MaxValueLength limits the length of baggage values. Longer values are
+	// truncated. Defaults to DefaultBaggageMaxValueLength.
+	MaxValueLength int
+}
+
+// BaggageTagMode controls which spans get the baggage of their trace as tags.
+type BaggageTagMode int
+
+const (
+	// BaggageTagsNone doesn't export baggage.
+	BaggageTagsNone BaggageTagMode = iota
+	// BaggageTagsLocalRoot exports baggage on the first span of the trace in
+	// each process.
+	BaggageTagsLocalRoot
+	// BaggageTagsAllSpans exports baggage on every span.
+	BaggageTagsAllSpans
+)
+
+type baggageKey struct{}
+
+type baggagePolicyKey struct{}
+
+// baggagePolicy is the BaggageOptions of a registry, which is attached to
+// its traces.
+type baggagePolicy struct {
+	allowed        map[string]bool
+	maxItems       int
+	maxValueLength int
+}
+
+// defaultBaggagePolicy restricts the baggage of traces that aren't observed
+// by a registry yet, e.g. while they are extracted.
+var defaultBaggagePolicy = newBaggagePolicy(BaggageOptions{})
+
+// baggageMu serializes baggage updates, which copy the map on write.
+var baggageMu sync.Mutex
+
+func newBaggagePolicy(opts BaggageOptions) *baggagePolicy {
+	policy := &baggagePolicy{
+		maxItems:       opts.MaxItems,
+		maxValueLength: opts.MaxValueLength,
+	}
+	if policy.maxItems <= 0 {
+		policy.maxItems = DefaultBaggageMaxItems
+	}
+	if policy.maxValueLength <= 0 {
+		policy.maxValueLength = DefaultBaggageMaxValueLength
+	}
+	if len(opts.AllowedKeys) > 0 {
+		policy.allowed = make(map[string]bool, len(opts.AllowedKeys))
+		for _, key := range opts.AllowedKeys {
+			policy.allowed[normalizeBaggageKey(key)] = true
+		}
+	}
+	return policy
+}
+
+// traceBaggagePolicy returns the policy of the registry that observes the
+// trace.
+func traceBaggagePolicy(trace *monkit.Trace) *baggagePolicy {
+	if policy, ok := trace.Get(baggagePolicyKey{}).(*baggagePolicy); ok {
+		return policy
+	}
+	return defaultBaggagePolicy
+}
+
+// attach makes the policy restrict the baggage of the trace, and applies it
+// to the baggage the trace already has, e.g. from the remote side.
+func (policy *baggagePolicy) attach(trace *monkit.Trace) {
+	baggageMu.Lock()
+	defer baggageMu.Unlock()
+
+	if _, ok := trace.Get(baggagePolicyKey{}).(*baggagePolicy); ok {
+		return
+	}
+	trace.Set(baggagePolicyKey{}, policy)
+
+	current, _ := trace.Get(baggageKey{}).(map[string]string)
+	if len(current) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(current))
+	for key := range current {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	baggage := make(map[string]string, len(current))
+	for _, key := range keys {
+		value, ok := policy.accept(key, current[key])
+		if !ok || len(baggage) >= policy.maxItems {
+			mon.Counter("jaeger_baggage_dropped").Inc(1)
+			continue
+		}
+		baggage[key] = value
+	}
+	trace.Set(baggageKey{}, baggage)
+}
+
+// accept returns the value of the baggage item, truncated if necessary, or
+// false if the key isn't allowed.
+func (policy *baggagePolicy) accept(key, value string) (string, bool) {
+	if policy.allowed != nil && !policy.allowed[key] {
+		return "", false
+	}
+	if len(value) > policy.maxValueLength {
+		mon.Counter("jaeger_baggage_truncated").Inc(1)
+		value = truncateUTF8(value, policy.maxValueLength)
+	}
+	return value, true
+}
+
+// normalizeBaggageKey lowercases keys, since carriers like http.Header don't
+// preserve their case.
+func normalizeBaggageKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+// SetBaggageItem sets a baggage item on the trace. Baggage is propagated
+// across process boundaries by the propagators that support it. It returns
+// false if the item was dropped because of the BaggageOptions of the registry
+// of the trace. Traces without a registry yet get the default BaggageOptions,
+// and the options of the registry are applied once it observes the trace.
+func SetBaggageItem(trace *monkit.Trace, key, value string) bool {
+	key = normalizeBaggageKey(key)
+	if key == "" {
+		return false
+	}
+
+	baggageMu.Lock()
+	defer baggageMu.Unlock()
+
+	policy := traceBaggagePolicy(trace)
+	value, ok := policy.accept(key, value)
+	if !ok {
+		mon.Counter("jaeger_baggage_dropped").Inc(1)
+		return false
+	}
+
+	current, _ := trace.Get(baggageKey{}).(map[string]string)
+	if _, exists := current[key]; !exists && len(current) >= policy.maxItems {
+		mon.Counter("jaeger_baggage_dropped").Inc(1)
+		return false
+	}
+
+	baggage := make(map[string]string, len(current)+1)
+	for k, v := range current {
+		baggage[k] = v
+	}
+	baggage[key] = value
+	trace.Set(baggageKey{}, baggage)
+	return true
+}
+
+// truncateUTF8 shortens s to at most n bytes without splitting a rune.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// BaggageItem returns the baggage item of the trace for key.
+func BaggageItem(trace *monkit.Trace, key string) string {
+	baggage, _ := trace.Get(baggageKey{}).(map[string]string)
+	return baggage[normalizeBaggageKey(key)]
+}
+
+// Baggage returns a copy of all baggage items of the trace.
+func Baggage(trace *monkit.Trace) map[string]string {
+	current, _ := trace.Get(baggageKey{}).(map[string]string)
+	baggage := make(map[string]string, len(current))
+	for k, v := range current {
+		baggage[k] = v
+	}
+	return baggage
+}
+
+// baggageTags returns the baggage of the trace as tags, sorted by key.
+func baggageTags(trace *monkit.Trace) []Tag {
+	baggage, _ := trace.Get(baggageKey{}).(map[string]string)
+	if len(baggage) == 0 {
+		return nil
+	}
+
+	tags := make([]Tag, 0, len(baggage))
+	for key, value := range baggage {
+		tags = append(tags, Tag{Key: BaggageTagPrefix + key, Value: value})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Key < tags[j].Key })
+	return tags
+}
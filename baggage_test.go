@@ -0,0 +1,102 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaggageOptions(t *testing.T) {
+	r := monkit.NewRegistry()
+	defer RegisterJaeger(r, &memoryCollector{}, Options{Fraction: 1, Baggage: BaggageOptions{
+		AllowedKeys:    []string{"Tenant", "project"},
+		MaxItems:       1,
+		MaxValueLength: 4,
+	}})()
+
+	// the options of other registries don't apply.
+	defer RegisterJaeger(monkit.NewRegistry(), &memoryCollector{}, Options{Baggage: BaggageOptions{MaxItems: 5}})()
+
+	ctx := context.Background()
+	defer r.Package().FuncNamed("root").Task(&ctx)(nil)
+	trace := monkit.SpanFromCtx(ctx).Trace()
+
+	require.False(t, SetBaggageItem(trace, "user", "alice"))
+	require.True(t, SetBaggageItem(trace, "TENANT", "tenant"))
+	require.False(t, SetBaggageItem(trace, "project", "p"))
+	require.Equal(t, map[string]string{"tenant": "tena"}, Baggage(trace))
+
+	// replacing an item doesn't count against the limit.
+	require.True(t, SetBaggageItem(trace, "tenant", "ab€"))
+	require.Equal(t, "ab", BaggageItem(trace, "Tenant"))
+
+	// baggage received before the registry observed the trace is restricted
+	// once it does.
+	remote := monkit.NewTrace(monkit.NewId())
+	require.True(t, SetBaggageItem(remote, "user", "alice"))
+	require.True(t, SetBaggageItem(remote, "tenant", "tenant"))
+	ctx = context.Background()
+	defer r.Package().FuncNamed("remote").RemoteTrace(&ctx, monkit.NewId(), remote)(nil)
+	require.Equal(t, map[string]string{"tenant": "tena"}, Baggage(remote))
+}
+
+func TestBaggageRemoteInfo(t *testing.T) {
+	trace := monkit.NewTrace(monkit.NewId())
+	trace.Set(Sampled, true)
+	require.True(t, SetBaggageItem(trace, "tenant", "project 1"))
+
+	withRemoteSpan(trace, 1, func(ctx context.Context, span *monkit.Span) {
+		remoteInfo := InjectRemoteInfo(ctx)
+		require.Equal(t, "project+1", remoteInfo[BaggagePrefix+"tenant"])
+
+		extracted, _ := RemoteTraceHandler(remoteInfo)
+		require.NotNil(t, extracted)
+		require.Equal(t, map[string]string{"tenant": "project 1"}, Baggage(extracted))
+	})
+}
+
+func TestBaggageTags(t *testing.T) {
+	for _, mode := range []BaggageTagMode{BaggageTagsNone, BaggageTagsLocalRoot, BaggageTagsAllSpans} {
+		r := monkit.NewRegistry()
+		collector := &memoryCollector{}
+		unregister := RegisterJaeger(r, collector, Options{Fraction: 1, BaggageTags: mode})
+
+		func() {
+			ctx := context.Background()
+			defer r.Package().FuncNamed("root").Task(&ctx)(nil)
+			SetBaggageItem(monkit.SpanFromCtx(ctx).Trace(), "tenant", "xxx")
+
+			defer r.Package().FuncNamed("child").Task(&ctx)(nil)
+		}()
+		unregister()
+
+		spans := collector.Spans()
+		require.Len(t, spans, 2)
+
+		var tagged []string
+		for _, span := range spans {
+			if tag, ok := findTag(BaggageTagPrefix+"tenant", span); ok {
+				require.Equal(t, "xxx", tag.GetVStr())
+				if span.ParentSpanId == 0 {
+					tagged = append(tagged, "root")
+				} else {
+					tagged = append(tagged, "child")
+				}
+			}
+		}
+
+		switch mode {
+		case BaggageTagsNone:
+			require.Empty(t, tagged)
+		case BaggageTagsLocalRoot:
+			require.Equal(t, []string{"root"}, tagged)
+		case BaggageTagsAllSpans:
+			require.ElementsMatch(t, []string{"root", "child"}, tagged)
+		}
+	}
+}
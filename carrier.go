@@ -0,0 +1,58 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import "net/http"
+
+// Carrier stores propagated trace information, e.g. HTTP headers or RPC
+// metadata.
+type Carrier interface {
+	// Get returns the value for key, or an empty string.
+	Get(key string) string
+	// Set sets the value for key.
+	Set(key, value string)
+	// Keys returns all keys in the carrier.
+	Keys() []string
+}
+
+// MapCarrier is a Carrier backed by a map, like the remote info used by
+// RemoteTraceHandler.
+type MapCarrier map[string]string
+
+var _ Carrier = MapCarrier{}
+
+// Get implements Carrier.
+func (c MapCarrier) Get(key string) string { return c[key] }
+
+// Set implements Carrier.
+func (c MapCarrier) Set(key, value string) { c[key] = value }
+
+// Keys implements Carrier.
+func (c MapCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// HeaderCarrier is a Carrier backed by HTTP headers.
+type HeaderCarrier http.Header
+
+var _ Carrier = HeaderCarrier{}
+
+// Get implements Carrier.
+func (c HeaderCarrier) Get(key string) string { return http.Header(c).Get(key) }
+
+// Set implements Carrier.
+func (c HeaderCarrier) Set(key, value string) { http.Header(c).Set(key, value) }
+
+// Keys implements Carrier.
+func (c HeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
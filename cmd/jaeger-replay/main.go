@@ -0,0 +1,114 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// jaeger-replay sends the trace files written by a FileTransport to a jaeger
+// agent, a jaeger collector, an OpenTelemetry collector or a Zipkin server.
+//
+// Usage:
+//
+//	jaeger-replay [-addr address] <file or directory>...
+//
+// The address is one the ThriftCollector accepts, e.g. "localhost:6831" for a
+// jaeger agent or "collector+http://collector:14268/api/thrift" for a jaeger
+// collector. Directories are replaced by the trace files in them, oldest
+// first.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	jaeger "storj.io/monkit-jaeger"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:6831", "the address to send the traces to")
+	packetSize := flag.Int("packet-size", 0, "the max packet size, or 0 for the default of the address")
+	otlpEncoding := flag.String("otlp-encoding", string(jaeger.OTLPEncodingProtobuf), "the encoding of OTLP requests, protobuf or json")
+	verbose := flag.Bool("verbose", false, "log debug messages")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] <file or directory>...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	log := zap.NewNop()
+	if *verbose {
+		var err error
+		log, err = zap.NewDevelopment()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%+v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := run(context.Background(), log, *addr, *packetSize, jaeger.OTLPEncoding(*otlpEncoding), flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "%+v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, log *zap.Logger, addr string, packetSize int, otlpEncoding jaeger.OTLPEncoding, paths []string) error {
+	// the collector is only used to open the transport for the address, the
+	// batches keep the process they were recorded with.
+	collector, err := jaeger.NewThriftCollector(log, addr, "jaeger-replay", nil, packetSize, 0, 0)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	collector.SetOTLPEncoding(otlpEncoding)
+
+	transport, err := collector.OpenTransport(ctx)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	defer transport.Close()
+
+	files, err := traceFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	var group errs.Group
+	total := 0
+	for _, file := range files {
+		spans, err := jaeger.ReplayFile(ctx, transport, file)
+		total += spans
+		fmt.Printf("%s: %d spans\n", file, spans)
+		if err != nil {
+			group.Add(errs.New("%s: %w", file, err))
+		}
+	}
+	fmt.Printf("sent %d spans from %d files\n", total, len(files))
+	return group.Err()
+}
+
+// traceFiles replaces the directories in paths with the trace files in them.
+func traceFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, errs.Wrap(err)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		dirFiles, err := jaeger.TraceFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, dirFiles...)
+	}
+	return files, nil
+}
@@ -7,7 +7,7 @@ package jaeger
 import "storj.io/monkit-jaeger/gen-go/jaeger"
 
 // TraceCollector is an interface dealing with completed Spans on a
-// SpanManager. See RegisterZipkin.
+// SpanManager. See RegisterJaeger.
 type TraceCollector interface {
 	// Collect gets called with a Span whenever a Span is completed on a
 	// SpanManager.
@@ -0,0 +1,225 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/monkit-jaeger/gen-go/jaeger"
+)
+
+// CollectorSchemePrefix is the prefix of the address schemes which make a
+// ThriftCollector send to the thrift service of a jaeger collector instead of
+// an agent, e.g. "collector+tcp://collector:14267" or
+// "collector+https://collector/api/thrift".
+const CollectorSchemePrefix = "collector+"
+
+const (
+	// defaultCollectorTimeout is the default timeout for connecting to the
+	// collector and for every call.
+	defaultCollectorTimeout = 10 * time.Second
+
+	// maxBatchesPerSubmit is the number of batches a ThriftCollector sends
+	// with a single call to a BatchesTransport.
+	maxBatchesPerSubmit = 8
+)
+
+// BatchesTransport is a Transport which can send several batches at once.
+type BatchesTransport interface {
+	Transport
+
+	// SendBatches sends out the batches. If only some of them failed, it
+	// returns a *BatchesError.
+	SendBatches(ctx context.Context, batches []*jaeger.Batch) error
+}
+
+// BatchesError is returned by BatchesTransport.SendBatches when only some of
+// the batches failed.
+type BatchesError struct {
+	// Errs has the error of each batch, which is nil for the batches that
+	// were sent.
+	Errs []error
+}
+
+// Error implements error.
+func (err *BatchesError) Error() string {
+	var failed []string
+	for _, batchErr := range err.Errs {
+		if batchErr != nil {
+			failed = append(failed, batchErr.Error())
+		}
+	}
+	return fmt.Sprintf("%d of %d batches failed: %s", len(failed), len(err.Errs), strings.Join(failed, "; "))
+}
+
+// batchErrors returns the error of each of n batches which were sent
+// together and failed with err.
+func batchErrors(err error, n int) []error {
+	result := make([]error, n)
+	var batchesErr *BatchesError
+	switch {
+	case errors.As(err, &batchesErr) && len(batchesErr.Errs) == n:
+		copy(result, batchesErr.Errs)
+	case err != nil:
+		for i := range result {
+			result[i] = err
+		}
+	}
+	return result
+}
+
+// ErrBatchRejected is the error class of batches the collector didn't accept.
+var ErrBatchRejected = errs.Class("batch rejected")
+
+// CollectorTransportOptions configures a CollectorTransport.
+type CollectorTransportOptions struct {
+	// Timeout limits connecting to the collector and every call. Defaults to
+	// 10s.
+	Timeout time.Duration
+	// HTTPClient sends the requests for http and https addresses. Defaults to
+	// a client with Timeout.
+	HTTPClient *http.Client
+}
+
+// CollectorTransport sends jaeger batches to the submitBatches method of the
+// thrift service of a jaeger collector, without an agent in between.
+type CollectorTransport struct {
+	log    *zap.Logger
+	opts   CollectorTransportOptions
+	open   func() (thrift.TTransport, error)
+	trans  thrift.TTransport
+	client *jaeger.CollectorClient
+}
+
+var _ BatchesTransport = &CollectorTransport{}
+
+// OpenCollectorTransport creates a new transport to the collector at
+// collectorAddr, which is either a "collector+tcp" address of the framed
+// thrift service, or a "collector+http" or "collector+https" URL. It connects
+// when the first batches are sent, so the collector doesn't have to be up yet.
+func OpenCollectorTransport(ctx context.Context, log *zap.Logger, collectorAddr string, opts CollectorTransportOptions) (*CollectorTransport, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultCollectorTimeout
+	}
+
+	parsed, err := url.Parse(collectorAddr)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+
+	t := &CollectorTransport{
+		log:  log,
+		opts: opts,
+	}
+
+	switch scheme := strings.TrimPrefix(parsed.Scheme, CollectorSchemePrefix); scheme {
+	case "tcp":
+		conf := &thrift.TConfiguration{
+			ConnectTimeout: opts.Timeout,
+			SocketTimeout:  opts.Timeout,
+		}
+		t.open = func() (thrift.TTransport, error) {
+			socket := thrift.NewTSocketConf(parsed.Host, conf)
+			trans := thrift.NewTFramedTransportConf(socket, conf)
+			return trans, trans.Open()
+		}
+	case "http", "https":
+		client := opts.HTTPClient
+		if client == nil {
+			client = &http.Client{Timeout: opts.Timeout}
+		}
+		parsed.Scheme = scheme
+		httpURL := parsed.String()
+		t.open = func() (thrift.TTransport, error) {
+			return thrift.NewTHttpClientWithOptions(httpURL, thrift.THttpClientOptions{Client: client})
+		}
+	default:
+		return nil, errs.New("unsupported collector address %q", collectorAddr)
+	}
+
+	return t, nil
+}
+
+// connect opens a new connection to the collector.
+func (t *CollectorTransport) connect() error {
+	trans, err := t.open()
+	if err != nil {
+		return err
+	}
+	t.trans = trans
+	t.client = jaeger.NewCollectorClientFactory(trans, thrift.NewTBinaryProtocolFactoryConf(nil))
+	return nil
+}
+
+// Send sends out the Jaeger spans.
+func (t *CollectorTransport) Send(ctx context.Context, batch *jaeger.Batch) error {
+	return t.SendBatches(ctx, []*jaeger.Batch{batch})
+}
+
+// SendBatches sends out the batches with a single call. Batches the collector
+// didn't accept fail with ErrBatchRejected, which is worth retrying.
+func (t *CollectorTransport) SendBatches(ctx context.Context, batches []*jaeger.Batch) error {
+	if t.client == nil {
+		// the previous call broke the connection.
+		if err := t.connect(); err != nil {
+			return errs.Wrap(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.opts.Timeout)
+	defer cancel()
+
+	responses, err := t.client.SubmitBatches(ctx, batches)
+	if err != nil {
+		t.disconnect()
+		return errs.Wrap(err)
+	}
+	if len(responses) != len(batches) {
+		return errs.New("collector responded for %d of %d batches", len(responses), len(batches))
+	}
+
+	batchErrs := make([]error, len(batches))
+	rejected := 0
+	for i, response := range responses {
+		if response == nil || !response.Ok {
+			batchErrs[i] = ErrBatchRejected.New("%d spans", len(batches[i].Spans))
+			rejected++
+		}
+	}
+	if rejected == 0 {
+		return nil
+	}
+
+	mon.Counter("jaeger_batch_rejected").Inc(int64(rejected))
+	if len(batches) == 1 {
+		return batchErrs[0]
+	}
+	return &BatchesError{Errs: batchErrs}
+}
+
+// disconnect closes the connection to the collector, so that the next call
+// opens a new one.
+func (t *CollectorTransport) disconnect() {
+	if err := t.trans.Close(); err != nil {
+		t.log.Debug("failed to close connection to Jaeger collector", zap.Error(err))
+	}
+	t.trans, t.client = nil, nil
+}
+
+// Close closes the transport.
+func (t *CollectorTransport) Close() {
+	if t.trans != nil {
+		t.disconnect()
+	}
+}
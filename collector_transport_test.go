@@ -0,0 +1,193 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"storj.io/common/testcontext"
+	"storj.io/monkit-jaeger/gen-go/jaeger"
+)
+
+// recordingCollector is a jaeger collector service which records the calls
+// to submitBatches. It rejects the batches of the service "reject" once.
+type recordingCollector struct {
+	mu       sync.Mutex
+	calls    [][]*jaeger.Batch
+	rejected map[int64]bool
+}
+
+func (collector *recordingCollector) SubmitBatches(ctx context.Context, batches []*jaeger.Batch) ([]*jaeger.BatchSubmitResponse, error) {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	collector.calls = append(collector.calls, batches)
+	responses := make([]*jaeger.BatchSubmitResponse, len(batches))
+	for i, batch := range batches {
+		ok := true
+		if batch.Process.ServiceName == "reject" && !collector.rejected[batch.GetSeqNo()] {
+			collector.rejected[batch.GetSeqNo()] = true
+			ok = false
+		}
+		responses[i] = &jaeger.BatchSubmitResponse{Ok: ok}
+	}
+	return responses, nil
+}
+
+func (collector *recordingCollector) Calls() [][]*jaeger.Batch {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	return append([][]*jaeger.Batch(nil), collector.calls...)
+}
+
+func newRecordingCollector() *recordingCollector {
+	return &recordingCollector{rejected: map[int64]bool{}}
+}
+
+func testBatch(service string, seqNo int64) *jaeger.Batch {
+	return &jaeger.Batch{
+		Process: &jaeger.Process{ServiceName: service},
+		Spans:   []*jaeger.Span{newTestSpan("submitted")},
+		SeqNo:   &seqNo,
+	}
+}
+
+func TestCollectorTransport(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	collector := newRecordingCollector()
+	processor := jaeger.NewCollectorProcessor(collector)
+
+	socket, err := thrift.NewTServerSocket("127.0.0.1:0")
+	require.NoError(t, err)
+	server := thrift.NewTSimpleServer4(processor, socket,
+		thrift.NewTFramedTransportFactoryConf(thrift.NewTTransportFactory(), nil),
+		thrift.NewTBinaryProtocolFactoryConf(nil))
+	require.NoError(t, server.Listen())
+	ctx.Go(server.Serve)
+	defer func() { _ = server.Stop() }()
+
+	httpServer := httptest.NewServer(http.HandlerFunc(thrift.NewThriftHandlerFunc(processor,
+		thrift.NewTBinaryProtocolFactoryConf(nil), thrift.NewTBinaryProtocolFactoryConf(nil))))
+	defer httpServer.Close()
+
+	for _, addr := range []string{
+		"collector+tcp://" + socket.Addr().String(),
+		"collector+http://" + strings.TrimPrefix(httpServer.URL, "http://"),
+	} {
+		transport, err := OpenCollectorTransport(ctx, zaptest.NewLogger(t), addr, CollectorTransportOptions{Timeout: time.Minute})
+		require.NoError(t, err)
+
+		calls := len(collector.Calls())
+		require.NoError(t, transport.Send(ctx, testBatch("test", 1)))
+
+		// the batches the collector didn't accept fail.
+		err = transport.SendBatches(ctx, []*jaeger.Batch{
+			testBatch("test", 2),
+			testBatch("reject", 3),
+			testBatch("test", 4),
+		})
+		var batchesErr *BatchesError
+		require.ErrorAs(t, err, &batchesErr)
+		require.Len(t, batchesErr.Errs, 3)
+		require.NoError(t, batchesErr.Errs[0])
+		require.True(t, ErrBatchRejected.Has(batchesErr.Errs[1]))
+		require.NoError(t, batchesErr.Errs[2])
+
+		require.True(t, ErrBatchRejected.Has(transport.Send(ctx, testBatch("reject", 5))))
+
+		received := collector.Calls()[calls:]
+		require.Len(t, received, 3)
+		require.Len(t, received[0], 1)
+		require.Len(t, received[1], 3)
+		require.Len(t, received[2], 1)
+
+		// the transport reconnects after a broken connection.
+		transport.disconnect()
+		require.NoError(t, transport.Send(ctx, testBatch("test", 6)))
+
+		transport.Close()
+		collector.rejected = map[int64]bool{}
+	}
+
+	_, err = OpenCollectorTransport(ctx, zaptest.NewLogger(t), "collector+udp://localhost:1234", CollectorTransportOptions{})
+	require.Error(t, err)
+}
+
+func TestCollectorTransportConnectsOnSend(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	// the collector isn't up yet when the transport is opened.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	transport, err := OpenCollectorTransport(ctx, zaptest.NewLogger(t), "collector+tcp://"+addr, CollectorTransportOptions{Timeout: time.Minute})
+	require.NoError(t, err)
+	require.Error(t, transport.Send(ctx, testBatch("test", 1)))
+
+	collector := newRecordingCollector()
+	socket, err := thrift.NewTServerSocket(addr)
+	require.NoError(t, err)
+	server := thrift.NewTSimpleServer4(jaeger.NewCollectorProcessor(collector), socket,
+		thrift.NewTFramedTransportFactoryConf(thrift.NewTTransportFactory(), nil),
+		thrift.NewTBinaryProtocolFactoryConf(nil))
+	require.NoError(t, server.Listen())
+	ctx.Go(server.Serve)
+	defer func() { _ = server.Stop() }()
+
+	require.NoError(t, transport.Send(ctx, testBatch("test", 2)))
+	require.Len(t, collector.Calls(), 1)
+	transport.Close()
+}
+
+func TestThriftCollectorSubmitsBatches(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	collector := newRecordingCollector()
+	server := httptest.NewServer(http.HandlerFunc(thrift.NewThriftHandlerFunc(jaeger.NewCollectorProcessor(collector),
+		thrift.NewTBinaryProtocolFactoryConf(nil), thrift.NewTBinaryProtocolFactoryConf(nil))))
+	defer server.Close()
+
+	addr := "collector+http://" + strings.TrimPrefix(server.URL, "http://")
+	thriftCollector, err := NewThriftCollector(zaptest.NewLogger(t), addr, "reject", nil, 0, 0, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, collectorTransportType, thriftCollector.transportType)
+
+	transport, err := OpenCollectorTransport(ctx, zaptest.NewLogger(t), addr, CollectorTransportOptions{})
+	require.NoError(t, err)
+	defer transport.Close()
+
+	// full batches wait to be sent together.
+	for i := 0; i < 3; i++ {
+		require.NoError(t, thriftCollector.handleSpan(ctx, newTestSpan("submitted"), transport))
+		require.NoError(t, thriftCollector.send(ctx, transport))
+	}
+	require.Empty(t, collector.Calls())
+	require.Len(t, thriftCollector.pendingBatches, 3)
+
+	// all batches are rejected once, and retried together.
+	require.Error(t, thriftCollector.Send(ctx, transport))
+	require.Len(t, collector.Calls(), 1)
+	require.Len(t, collector.Calls()[0], 3)
+	require.Len(t, thriftCollector.retries, 3)
+
+	require.NoError(t, thriftCollector.Retry(ctx, transport, time.Now().Add(time.Hour)))
+	require.Len(t, collector.Calls(), 2)
+	require.Len(t, collector.Calls()[1], 3)
+	require.Empty(t, thriftCollector.retries)
+}
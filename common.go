@@ -22,4 +22,13 @@ const (
 	// TraceHost is the host to send the traces to. If unprovided, the default
 	// is used.
 	TraceHost = "trace-host"
+	// TraceIDHigh is the key we use to store the high 64 bits of 128-bit trace
+	// ids into context.
+	TraceIDHigh = "trace-id-high"
+	// TraceState is the key we use to store the W3C tracestate into context.
+	TraceState = "tracestate"
+	// SamplingDeferred marks traces without a sampling decision yet, e.g.
+	// while the tail sampler decides. Sampled is false for them, for services
+	// that don't know this key.
+	SamplingDeferred = "sampling-deferred"
 )
@@ -5,6 +5,13 @@
 /*
 Package jaeger provides a monkit plugin for sending traces to Jaeger Agent.
 
+Besides a jaeger agent, the ThriftCollector can send to a jaeger collector,
+an OpenTelemetry collector or a Zipkin server, or write to local files,
+depending on the scheme of its address, e.g. "collector+tcp://collector:14267",
+"otlp+http://collector:4318", "zipkin+http://zipkin:9411" or
+"file:///var/lib/traces". The jaeger-replay command sends the files to any of
+the others.
+
 # Example usage
 
 Your main method gets set up something like this:
@@ -12,24 +19,25 @@ Your main method gets set up something like this:
 	  package main
 
 	  import (
-		  "net/http"
+		  "context"
 
 		  jaeger "storj.io/monkit-jaeger"
 		  "github.com/spacemonkeygo/monkit/v3"
 		  "github.com/spacemonkeygo/monkit/v3/environment"
-		  "github.com/spacemonkeygo/monkit/v3/present"
+		  "go.uber.org/zap"
 	  )
 
 	  func main() {
 		  environment.Register(monkit.Default)
-		  collector, err := jaeger.NewThriftCollector("zipkin.whatever:9042", 200, "service name", []jaeger.Tag{
+		  collector, err := jaeger.NewThriftCollector(zap.L(), "localhost:6831", "service name", []jaeger.Tag{
 			  jaeger.Tag{
 				  ....
 			  }
-		  })
+		  }, 0, 0, 0)
 		  if err != nil {
 			  panic(err)
 		  }
+		  go collector.Run(context.Background())
 		  jaeger.RegisterJaeger(monkit.Default, collector, jaeger.Options{
 			  Fraction: 1})
 
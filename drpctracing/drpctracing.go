@@ -0,0 +1,283 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package drpctracing implements trace propagation and span tagging for DRPC
+// clients and servers.
+package drpctracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/spacemonkeygo/monkit/v3"
+
+	"storj.io/common/rpc/rpcpeer"
+	"storj.io/common/rpc/rpcstatus"
+	"storj.io/drpc"
+	"storj.io/drpc/drpcctx"
+	"storj.io/drpc/drpcmetadata"
+	jaeger "storj.io/monkit-jaeger"
+)
+
+var mon = monkit.Package()
+
+const (
+	// SpanKindTag is the tag that distinguishes server and client spans.
+	SpanKindTag = jaeger.SpanKindTag
+	// CodeTag is the tag with the rpc status code.
+	CodeTag = "rpc.code"
+	// SentBytesTag is the tag with the number of encoded message bytes sent.
+	SentBytesTag = "rpc.sent_bytes"
+	// ReceivedBytesTag is the tag with the number of encoded message bytes
+	// received.
+	ReceivedBytesTag = "rpc.received_bytes"
+	// PeerAddressTag is the tag with the address of the remote peer.
+	PeerAddressTag = "peer.address"
+)
+
+// Options configures the tracing Conn and Handler.
+type Options struct {
+	// Scope is used to create the spans, which are named after the rpc.
+	// Defaults to the scope of this package.
+	Scope *monkit.Scope
+
+	// Propagators are the formats to read and write the trace information.
+	// Defaults to the jaeger.RemoteInfoPropagator.
+	Propagators []jaeger.Propagator
+}
+
+func (opts Options) scope() *monkit.Scope {
+	if opts.Scope == nil {
+		return mon
+	}
+	return opts.Scope
+}
+
+func (opts Options) inject(ctx context.Context) context.Context {
+	metadata := jaeger.MapCarrier{}
+	if len(opts.Propagators) == 0 {
+		jaeger.RemoteInfoPropagator{}.Inject(ctx, metadata)
+	} else {
+		jaeger.CompositePropagator(opts.Propagators).Inject(ctx, metadata)
+	}
+	if len(metadata) == 0 {
+		return ctx
+	}
+	return drpcmetadata.AddPairs(ctx, metadata)
+}
+
+// Conn wraps a drpc.Conn to start a client span for every rpc and send the
+// trace information along.
+type Conn struct {
+	drpc.Conn
+	opts Options
+}
+
+// NewConn returns a new instance of Conn.
+func NewConn(conn drpc.Conn, opts Options) *Conn {
+	return &Conn{
+		Conn: conn,
+		opts: opts,
+	}
+}
+
+// Invoke implements drpc.Conn's Invoke method with a client span.
+func (c *Conn) Invoke(ctx context.Context, rpc string, enc drpc.Encoding, in, out drpc.Message) (err error) {
+	defer c.opts.scope().FuncNamed(rpc).Task(&ctx)(&err)
+
+	jaeger.SetSpanKind(monkit.SpanFromCtx(ctx), jaeger.SpanKindClient)
+
+	counter := &byteCounter{}
+	defer func() { annotate(ctx, c.peerAddress(), counter, err) }()
+
+	return c.Conn.Invoke(c.opts.inject(ctx), rpc, counter.wrap(enc), in, out)
+}
+
+// NewStream implements drpc.Conn's NewStream method with a client span, that
+// finishes when the stream is done.
+func (c *Conn) NewStream(ctx context.Context, rpc string, enc drpc.Encoding) (_ drpc.Stream, err error) {
+	stream := &clientStream{counter: &byteCounter{}}
+	finish := c.opts.scope().FuncNamed(rpc).Task(&ctx)
+	spanCtx := ctx
+	jaeger.SetSpanKind(monkit.SpanFromCtx(ctx), jaeger.SpanKindClient)
+
+	stream.Stream, err = c.Conn.NewStream(c.opts.inject(ctx), rpc, enc)
+	if err != nil {
+		annotate(spanCtx, c.peerAddress(), stream.counter, err)
+		finish(&err)
+		return nil, err
+	}
+
+	go func() {
+		<-stream.Context().Done()
+		err := stream.err()
+		annotate(spanCtx, c.peerAddress(), stream.counter, err)
+		finish(&err)
+	}()
+
+	return stream, nil
+}
+
+// peerAddress returns the remote address, if the underlying connection
+// exposes its transport.
+func (c *Conn) peerAddress() string {
+	transporter, ok := c.Conn.(interface{ Transport() drpc.Transport })
+	if !ok {
+		return ""
+	}
+	if conn, ok := transporter.Transport().(net.Conn); ok && conn.RemoteAddr() != nil {
+		return conn.RemoteAddr().String()
+	}
+	return ""
+}
+
+// clientStream counts the message bytes and remembers the first failure.
+type clientStream struct {
+	drpc.Stream
+	counter *byteCounter
+	failure atomic.Value // error
+}
+
+func (s *clientStream) MsgSend(msg drpc.Message, enc drpc.Encoding) error {
+	return s.record(s.Stream.MsgSend(msg, s.counter.wrap(enc)))
+}
+
+func (s *clientStream) MsgRecv(msg drpc.Message, enc drpc.Encoding) error {
+	return s.record(s.Stream.MsgRecv(msg, s.counter.wrap(enc)))
+}
+
+func (s *clientStream) record(err error) error {
+	if err != nil && !errors.Is(err, io.EOF) {
+		s.failure.CompareAndSwap(nil, errorValue{err})
+	}
+	return err
+}
+
+func (s *clientStream) err() error {
+	if v, ok := s.failure.Load().(errorValue); ok {
+		return v.err
+	}
+	return nil
+}
+
+// errorValue wraps errors, so they have a consistent type in atomic.Value.
+type errorValue struct{ err error }
+
+// Handler implements drpc.Handler and starts a server span for every rpc,
+// which continues the trace of the client.
+type Handler struct {
+	handler drpc.Handler
+	opts    Options
+}
+
+// NewHandler returns a new instance of Handler.
+func NewHandler(handler drpc.Handler, opts Options) *Handler {
+	return &Handler{
+		handler: handler,
+		opts:    opts,
+	}
+}
+
+// HandleRPC implements drpc.Handler.
+func (h *Handler) HandleRPC(stream drpc.Stream, rpc string) (err error) {
+	ctx := stream.Context()
+	f := h.opts.scope().FuncNamed(rpc)
+
+	var trace *monkit.Trace
+	var parentID int64
+	if metadata, ok := drpcmetadata.Get(ctx); ok {
+		trace, parentID = jaeger.RemoteTraceHandler(metadata, h.opts.Propagators...)
+	}
+	if trace != nil {
+		defer f.RemoteTrace(&ctx, parentID, trace)(&err)
+	} else {
+		defer f.ResetTrace(&ctx)(&err)
+	}
+
+	jaeger.SetSpanKind(monkit.SpanFromCtx(ctx), jaeger.SpanKindServer)
+	address := peerAddress(ctx)
+
+	counter := &byteCounter{}
+	defer func() { annotate(ctx, address, counter, err) }()
+
+	return h.handler.HandleRPC(&serverStream{Stream: stream, ctx: ctx, counter: counter}, rpc)
+}
+
+// peerAddress returns the remote address of the server stream context. Not
+// every transport has a tls connection state, which rpcpeer requires, so the
+// transport is checked directly as well.
+func peerAddress(ctx context.Context) string {
+	if peer, err := rpcpeer.FromContext(ctx); err == nil && peer.Addr != nil {
+		return peer.Addr.String()
+	}
+	tr, ok := drpcctx.Transport(ctx)
+	if !ok {
+		return ""
+	}
+	if conn, ok := tr.(interface{ RemoteAddr() net.Addr }); ok && conn.RemoteAddr() != nil {
+		return conn.RemoteAddr().String()
+	}
+	return ""
+}
+
+// serverStream replaces the context of the stream with the span context and
+// counts the message bytes.
+type serverStream struct {
+	drpc.Stream
+	ctx     context.Context
+	counter *byteCounter
+}
+
+func (s *serverStream) Context() context.Context { return s.ctx }
+
+func (s *serverStream) MsgSend(msg drpc.Message, enc drpc.Encoding) error {
+	return s.Stream.MsgSend(msg, s.counter.wrap(enc))
+}
+
+func (s *serverStream) MsgRecv(msg drpc.Message, enc drpc.Encoding) error {
+	return s.Stream.MsgRecv(msg, s.counter.wrap(enc))
+}
+
+// annotate adds the rpc tags to the span in ctx.
+func annotate(ctx context.Context, address string, counter *byteCounter, err error) {
+	span := monkit.SpanFromCtx(ctx)
+	if span == nil {
+		return
+	}
+	span.Annotate(CodeTag, strconv.FormatUint(uint64(rpcstatus.Code(err)), 10))
+	span.Annotate(SentBytesTag, strconv.FormatInt(counter.sent.Load(), 10))
+	span.Annotate(ReceivedBytesTag, strconv.FormatInt(counter.received.Load(), 10))
+	if address != "" {
+		span.Annotate(PeerAddressTag, address)
+	}
+}
+
+// byteCounter counts the encoded message sizes.
+type byteCounter struct {
+	sent     atomic.Int64
+	received atomic.Int64
+}
+
+func (c *byteCounter) wrap(enc drpc.Encoding) drpc.Encoding {
+	return &countingEncoding{Encoding: enc, counter: c}
+}
+
+type countingEncoding struct {
+	drpc.Encoding
+	counter *byteCounter
+}
+
+func (e *countingEncoding) Marshal(msg drpc.Message) ([]byte, error) {
+	data, err := e.Encoding.Marshal(msg)
+	e.counter.sent.Add(int64(len(data)))
+	return data, err
+}
+
+func (e *countingEncoding) Unmarshal(buf []byte, msg drpc.Message) error {
+	e.counter.received.Add(int64(len(buf)))
+	return e.Encoding.Unmarshal(buf, msg)
+}
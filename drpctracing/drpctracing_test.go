@@ -0,0 +1,161 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package drpctracing_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/rpc/rpcstatus"
+	"storj.io/common/testcontext"
+	"storj.io/drpc"
+	"storj.io/drpc/drpcconn"
+	"storj.io/drpc/drpcserver"
+	jaeger "storj.io/monkit-jaeger"
+	"storj.io/monkit-jaeger/drpctracing"
+	jaegerthrift "storj.io/monkit-jaeger/gen-go/jaeger"
+)
+
+// collectorFunc is a jaeger.TraceCollector calling a func.
+type collectorFunc func(span *jaegerthrift.Span)
+
+func (f collectorFunc) Collect(span *jaegerthrift.Span) { f(span) }
+
+// recordSpans returns a collector which records the finished spans, and a
+// func which finds the last recorded span with the name and kind. find waits
+// for the span, since the server span can finish after the client got the
+// response.
+func recordSpans(t *testing.T) (jaeger.TraceCollector, func(name, kind string) *jaegerthrift.Span) {
+	var mu sync.Mutex
+	var spans []*jaegerthrift.Span
+	collector := collectorFunc(func(span *jaegerthrift.Span) {
+		mu.Lock()
+		defer mu.Unlock()
+		spans = append(spans, span)
+	})
+	find := func(name, kind string) *jaegerthrift.Span {
+		var found *jaegerthrift.Span
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			for i := len(spans) - 1; i >= 0; i-- {
+				if spans[i].OperationName == name && tags(spans[i])[drpctracing.SpanKindTag] == kind {
+					found = spans[i]
+					return true
+				}
+			}
+			return false
+		}, 5*time.Second, time.Millisecond, "%s %s", name, kind)
+		return found
+	}
+	return collector, find
+}
+
+func tags(span *jaegerthrift.Span) map[string]string {
+	tags := map[string]string{}
+	for _, tag := range span.Tags {
+		tags[tag.Key] = tag.GetVStr()
+	}
+	return tags
+}
+
+// stringEncoding encodes *string messages.
+type stringEncoding struct{}
+
+func (stringEncoding) Marshal(msg drpc.Message) ([]byte, error) {
+	return []byte(*msg.(*string)), nil
+}
+
+func (stringEncoding) Unmarshal(buf []byte, msg drpc.Message) error {
+	*msg.(*string) = string(buf)
+	return nil
+}
+
+// echoHandler replies with the request, or fails for the "fail" rpc.
+type echoHandler struct{}
+
+func (echoHandler) HandleRPC(stream drpc.Stream, rpc string) error {
+	var in string
+	if err := stream.MsgRecv(&in, stringEncoding{}); err != nil {
+		return err
+	}
+	if rpc == "fail" {
+		return rpcstatus.Error(rpcstatus.NotFound, "missing")
+	}
+	return stream.MsgSend(&in, stringEncoding{})
+}
+
+func TestConnAndHandler(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	r := monkit.NewRegistry()
+	collector, find := recordSpans(t)
+	defer jaeger.RegisterJaeger(r, collector, jaeger.Options{Fraction: 1})()
+
+	opts := drpctracing.Options{Scope: r.ScopeNamed("test")}
+
+	clientConn, serverConn := net.Pipe()
+	srv := drpcserver.New(drpctracing.NewHandler(echoHandler{}, opts))
+	ctx.Go(func() error {
+		_ = srv.ServeOne(ctx, serverConn)
+		return nil
+	})
+
+	conn := drpctracing.NewConn(drpcconn.New(clientConn), opts)
+	defer func() { require.NoError(t, conn.Close()) }()
+
+	invoke := func(rpc string) error {
+		ctx := context.Background()
+		defer r.ScopeNamed("test").FuncNamed("root").Task(&ctx)(nil)
+
+		in, out := "hello", ""
+		err := conn.Invoke(ctx, rpc, stringEncoding{}, &in, &out)
+		if err == nil {
+			require.Equal(t, in, out)
+		}
+		return err
+	}
+
+	require.NoError(t, invoke("echo"))
+
+	root := find("test.root", "")
+	client := find("test.echo", "client")
+	server := find("test.echo", "server")
+
+	require.Equal(t, root.TraceIdLow, client.TraceIdLow)
+	require.Equal(t, root.TraceIdLow, server.TraceIdLow)
+	require.Equal(t, root.SpanId, client.ParentSpanId)
+	require.Equal(t, client.SpanId, server.ParentSpanId)
+
+	require.Equal(t, map[string]string{
+		drpctracing.SpanKindTag:      "client",
+		drpctracing.CodeTag:          "1",
+		drpctracing.SentBytesTag:     "5",
+		drpctracing.ReceivedBytesTag: "5",
+		drpctracing.PeerAddressTag:   "pipe",
+	}, tags(client))
+	require.Equal(t, map[string]string{
+		drpctracing.SpanKindTag:      "server",
+		drpctracing.CodeTag:          "1",
+		drpctracing.SentBytesTag:     "5",
+		drpctracing.ReceivedBytesTag: "5",
+		drpctracing.PeerAddressTag:   "pipe",
+	}, tags(server))
+
+	err := invoke("fail")
+	require.Error(t, err)
+	require.Equal(t, rpcstatus.NotFound, rpcstatus.Code(err))
+
+	for _, kind := range []string{"client", "server"} {
+		span := find("test.fail", kind)
+		require.Equal(t, "5", tags(span)[drpctracing.CodeTag])
+		require.Equal(t, "errored", tags(span)["status"])
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"reflect"
+	"syscall"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/common/rpc/rpcstatus"
+)
+
+// ErrorKindTag is the tag and log field the class of a span error is
+// recorded as.
+const ErrorKindTag = "error.kind"
+
+// ErrorClassifier returns a class for err, which is exported with the span
+// that failed with err. The class must never contain the error message, which
+// may contain private information. It returns false if it can't classify err.
+type ErrorClassifier func(err error) (class string, ok bool)
+
+// ChainErrorClassifiers returns an ErrorClassifier that returns the class of
+// the first classifier that can classify the error.
+func ChainErrorClassifiers(classifiers ...ErrorClassifier) ErrorClassifier {
+	return func(err error) (string, bool) {
+		for _, classifier := range classifiers {
+			if classifier == nil {
+				continue
+			}
+			if class, ok := classifier(err); ok {
+				return class, true
+			}
+		}
+		return "", false
+	}
+}
+
+// DefaultErrorClassifier is the ErrorClassifier used if none is configured.
+var DefaultErrorClassifier = ChainErrorClassifiers(
+	ClassifyContextError,
+	ClassifyRPCStatus,
+	ClassifyFSError,
+	ClassifyErrno,
+	ClassifyErrsClass,
+)
+
+// ClassifyContextError classifies context cancellation and deadlines.
+func ClassifyContextError(err error) (string, bool) {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "context.Canceled", true
+	case errors.Is(err, context.DeadlineExceeded):
+		return "context.DeadlineExceeded", true
+	default:
+		return "", false
+	}
+}
+
+var rpcStatusNames = map[rpcstatus.StatusCode]string{
+	rpcstatus.Canceled:           "Canceled",
+	rpcstatus.InvalidArgument:    "InvalidArgument",
+	rpcstatus.DeadlineExceeded:   "DeadlineExceeded",
+	rpcstatus.NotFound:           "NotFound",
+	rpcstatus.AlreadyExists:      "AlreadyExists",
+	rpcstatus.PermissionDenied:   "PermissionDenied",
+	rpcstatus.ResourceExhausted:  "ResourceExhausted",
+	rpcstatus.FailedPrecondition: "FailedPrecondition",
+	rpcstatus.Aborted:            "Aborted",
+	rpcstatus.OutOfRange:         "OutOfRange",
+	rpcstatus.Unimplemented:      "Unimplemented",
+	rpcstatus.Internal:           "Internal",
+	rpcstatus.Unavailable:        "Unavailable",
+	rpcstatus.DataLoss:           "DataLoss",
+	rpcstatus.Unauthenticated:    "Unauthenticated",
+}
+
+// ClassifyRPCStatus classifies errors with an rpc status code.
+func ClassifyRPCStatus(err error) (string, bool) {
+	code := rpcstatus.Code(err)
+	if code == rpcstatus.Unknown || code == rpcstatus.OK {
+		return "", false
+	}
+	if name, ok := rpcStatusNames[code]; ok {
+		return "rpcstatus." + name, true
+	}
+	return fmt.Sprintf("rpcstatus.Code(%d)", code), true
+}
+
+// ClassifyFSError classifies the file system errors of io/fs and io.
+func ClassifyFSError(err error) (string, bool) {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return "fs.ErrNotExist", true
+	case errors.Is(err, fs.ErrExist):
+		return "fs.ErrExist", true
+	case errors.Is(err, fs.ErrPermission):
+		return "fs.ErrPermission", true
+	case errors.Is(err, fs.ErrClosed):
+		return "fs.ErrClosed", true
+	case errors.Is(err, fs.ErrInvalid):
+		return "fs.ErrInvalid", true
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return "io.ErrUnexpectedEOF", true
+	case errors.Is(err, io.EOF):
+		return "io.EOF", true
+	default:
+		return "", false
+	}
+}
+
+var errnoNames = map[syscall.Errno]string{
+	syscall.EACCES:       "EACCES",
+	syscall.EADDRINUSE:   "EADDRINUSE",
+	syscall.EAGAIN:       "EAGAIN",
+	syscall.EBADF:        "EBADF",
+	syscall.ECONNABORTED: "ECONNABORTED",
+	syscall.ECONNREFUSED: "ECONNREFUSED",
+	syscall.ECONNRESET:   "ECONNRESET",
+	syscall.EEXIST:       "EEXIST",
+	syscall.EHOSTUNREACH: "EHOSTUNREACH",
+	syscall.EINTR:        "EINTR",
+	syscall.EINVAL:       "EINVAL",
+	syscall.EIO:          "EIO",
+	syscall.EISDIR:       "EISDIR",
+	syscall.EMFILE:       "EMFILE",
+	syscall.ENETUNREACH:  "ENETUNREACH",
+	syscall.ENFILE:       "ENFILE",
+	syscall.ENOENT:       "ENOENT",
+	syscall.ENOSPC:       "ENOSPC",
+	syscall.ENOTCONN:     "ENOTCONN",
+	syscall.ENOTDIR:      "ENOTDIR",
+	syscall.ENOTEMPTY:    "ENOTEMPTY",
+	syscall.EPERM:        "EPERM",
+	syscall.EPIPE:        "EPIPE",
+	syscall.EROFS:        "EROFS",
+	syscall.ETIMEDOUT:    "ETIMEDOUT",
+}
+
+// ClassifyErrno classifies syscall errors by their errno name.
+func ClassifyErrno(err error) (string, bool) {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return "", false
+	}
+	if name, ok := errnoNames[errno]; ok {
+		return "syscall." + name, true
+	}
+	return fmt.Sprintf("syscall.Errno(%d)", uint64(errno)), true
+}
+
+// ClassifyErrsClass classifies errors by the outermost errs.Class that
+// wrapped them.
+func ClassifyErrsClass(err error) (string, bool) {
+	for _, class := range errs.Classes(err) {
+		if *class != "" {
+			return string(*class), true
+		}
+	}
+	return "", false
+}
+
+// ErrorTypeClassifier returns an ErrorClassifier that classifies errors by
+// their type, if it's the type of one of the examples. Only types whose
+// names can't leak private information should be allowed.
+func ErrorTypeClassifier(examples ...error) ErrorClassifier {
+	allowed := make(map[reflect.Type]bool, len(examples))
+	for _, example := range examples {
+		allowed[reflect.TypeOf(example)] = true
+	}
+
+	return func(err error) (string, bool) {
+		for i := 0; err != nil && i < maxErrorDepth; i++ {
+			if typ := reflect.TypeOf(err); allowed[typ] {
+				return typ.String(), true
+			}
+			err = errors.Unwrap(err)
+		}
+		return "", false
+	}
+}
+
+// maxErrorDepth limits how many wrapped errors ErrorTypeClassifier looks at.
+const maxErrorDepth = 100
+
+// classifyError returns the class of a span error, or an empty string if it
+// can't be classified.
+func (srv *service) classifyError(spanErr error, panicked bool) string {
+	if panicked {
+		return "panic"
+	}
+	if spanErr == nil {
+		return ""
+	}
+
+	classifier := srv.ErrorClassifier
+	if classifier == nil {
+		classifier = DefaultErrorClassifier
+	}
+	class, ok := classifier(spanErr)
+	if !ok {
+		return ""
+	}
+	return class
+}
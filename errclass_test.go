@@ -0,0 +1,123 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/errs"
+
+	"storj.io/common/rpc/rpcstatus"
+)
+
+var errMetainfo = errs.Class("metainfo")
+
+func TestDefaultErrorClassifier(t *testing.T) {
+	_, statErr := os.Stat("/this/path/does/not/exist")
+
+	for _, test := range []struct {
+		err   error
+		class string
+	}{
+		{context.Canceled, "context.Canceled"},
+		{fmt.Errorf("secret: %w", context.DeadlineExceeded), "context.DeadlineExceeded"},
+		{rpcstatus.Error(rpcstatus.NotFound, "secret"), "rpcstatus.NotFound"},
+		{statErr, "fs.ErrNotExist"},
+		{errs.Wrap(io.ErrUnexpectedEOF), "io.ErrUnexpectedEOF"},
+		{&net.OpError{Op: "read", Err: syscall.ECONNRESET}, "syscall.ECONNRESET"},
+		{errMetainfo.Wrap(errors.New("secret")), "metainfo"},
+	} {
+		class, ok := DefaultErrorClassifier(test.err)
+		require.True(t, ok, test.class)
+		require.Equal(t, test.class, class)
+	}
+
+	_, ok := DefaultErrorClassifier(errors.New("secret"))
+	require.False(t, ok)
+	_, ok = DefaultErrorClassifier(errs.New("secret"))
+	require.False(t, ok)
+}
+
+type allowedError struct{ msg string }
+
+func (err *allowedError) Error() string { return err.msg }
+
+func TestErrorTypeClassifier(t *testing.T) {
+	classifier := ChainErrorClassifiers(nil, ClassifyContextError, ErrorTypeClassifier(&allowedError{}))
+
+	class, ok := classifier(fmt.Errorf("wrapped: %w", &allowedError{msg: "secret"}))
+	require.True(t, ok)
+	require.Equal(t, "*jaeger.allowedError", class)
+
+	class, ok = classifier(fmt.Errorf("%w: %v", context.Canceled, &allowedError{}))
+	require.True(t, ok)
+	require.Equal(t, "context.Canceled", class)
+
+	_, ok = classifier(errors.New("secret"))
+	require.False(t, ok)
+}
+
+func TestRegisterJaegerErrorKind(t *testing.T) {
+	r := monkit.NewRegistry()
+	collector := &memoryCollector{}
+	unregister := RegisterJaeger(r, collector, Options{
+		Fraction:        1,
+		ErrorClassifier: ChainErrorClassifiers(ErrorTypeClassifier(&allowedError{}), DefaultErrorClassifier),
+	})
+	defer unregister()
+
+	for _, test := range []struct {
+		err   error
+		class string
+	}{
+		{&allowedError{msg: "secret"}, "*jaeger.allowedError"},
+		{errMetainfo.New("secret"), "metainfo"},
+		{errors.New("secret"), ""},
+	} {
+		collector.spans = nil
+		func() {
+			ctx := context.Background()
+			err := test.err
+			defer r.Package().FuncNamed("failing").Task(&ctx)(&err)
+		}()
+
+		spans := collector.Spans()
+		require.Len(t, spans, 1)
+		span := spans[0]
+
+		for _, tag := range span.Tags {
+			require.NotContains(t, tag.GetVStr(), "secret")
+		}
+
+		tag, ok := findTag(ErrorKindTag, span)
+		if test.class == "" {
+			require.False(t, ok)
+			require.Empty(t, span.Logs)
+			continue
+		}
+		require.True(t, ok)
+		require.Equal(t, test.class, tag.GetVStr())
+
+		_, ok = findTag("error", span)
+		require.True(t, ok)
+
+		require.Len(t, span.Logs, 1)
+		var fields []string
+		for _, field := range span.Logs[0].Fields {
+			require.False(t, strings.Contains(field.GetVStr(), "secret"))
+			fields = append(fields, field.Key+"="+field.GetVStr())
+		}
+		require.Contains(t, fields, ErrorKindTag+"="+test.class)
+	}
+}
@@ -0,0 +1,393 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/monkit-jaeger/gen-go/jaeger"
+)
+
+// FileScheme is the address scheme which makes a ThriftCollector write to
+// local files, e.g. "file:///var/lib/traces".
+const FileScheme = "file"
+
+// FileFormat is the format of the files of a FileTransport.
+type FileFormat string
+
+const (
+	// FileFormatThrift writes every batch as its length in four big-endian
+	// bytes, followed by the batch encoded with the thrift binary protocol.
+	FileFormatThrift FileFormat = "thrift"
+	// FileFormatJSON writes every batch as a line of JSON.
+	FileFormatJSON FileFormat = "json"
+)
+
+// extension returns the file extension of the format.
+func (format FileFormat) extension() string {
+	if format == FileFormatJSON {
+		return ".jsonl"
+	}
+	return ".thrift"
+}
+
+const (
+	// traceFilePrefix is the prefix of the names of the trace files, which
+	// is followed by the time they were created.
+	traceFilePrefix = "traces-"
+	// traceFileTimeLayout sorts the trace files by the time they were
+	// created.
+	traceFileTimeLayout = "20060102T150405.000000000"
+
+	// maxTraceFileRecordSize limits the size of the records read from thrift
+	// trace files, to detect corrupted files.
+	maxTraceFileRecordSize = 64 << 20
+
+	// defaultFileMaxSize is the default size files are rotated at.
+	defaultFileMaxSize = 16 << 20
+	// defaultFileMaxAge is the default age files are rotated at.
+	defaultFileMaxAge = 24 * time.Hour
+	// defaultFileMaxFiles is the default number of files which are kept.
+	defaultFileMaxFiles = 10
+)
+
+// FileTransportOptions configures a FileTransport.
+type FileTransportOptions struct {
+	// Format is the format of the files. Defaults to FileFormatThrift.
+	Format FileFormat
+
+	// MaxFileSize is the size a file is rotated at. Defaults to 16MiB.
+	MaxFileSize int64
+	// MaxFileAge is the age a file is rotated at. Defaults to 24h, and
+	// negative disables rotating by age.
+	MaxFileAge time.Duration
+
+	// MaxFiles is the number of files which are kept, including the current
+	// one. The oldest files are removed. Defaults to 10, and negative keeps
+	// all files.
+	MaxFiles int
+	// Retention is how long files are kept after they were last written.
+	// Files are kept regardless of their age if zero.
+	Retention time.Duration
+}
+
+// FileTransport writes jaeger batches to local files in a directory, so that
+// they can be replayed with ReplayFile later. The files are rotated when they
+// get too large or too old.
+type FileTransport struct {
+	log  *zap.Logger
+	dir  string
+	opts FileTransportOptions
+
+	file   traceFile
+	size   int64
+	opened time.Time
+
+	buffer   *thrift.TMemoryBuffer
+	protocol thrift.TProtocol
+}
+
+var _ Transport = &FileTransport{}
+
+// traceFile is the file a FileTransport writes to.
+type traceFile interface {
+	io.WriteCloser
+	io.Seeker
+	Truncate(size int64) error
+	Name() string
+}
+
+// OpenFileTransport creates a new transport which writes to files in dir,
+// which may be a "file" URL. The first file is created with the first batch.
+func OpenFileTransport(ctx context.Context, log *zap.Logger, dir string, opts FileTransportOptions) (*FileTransport, error) {
+	if strings.HasPrefix(dir, FileScheme+":") {
+		parsed, err := url.Parse(dir)
+		if err != nil {
+			return nil, errs.Wrap(err)
+		}
+		dir = filepath.FromSlash(parsed.Path)
+	}
+
+	switch opts.Format {
+	case "":
+		opts.Format = FileFormatThrift
+	case FileFormatThrift, FileFormatJSON:
+	default:
+		return nil, errs.New("unsupported file format %q", opts.Format)
+	}
+	if opts.MaxFileSize <= 0 {
+		opts.MaxFileSize = defaultFileMaxSize
+	}
+	if opts.MaxFileAge == 0 {
+		opts.MaxFileAge = defaultFileMaxAge
+	}
+	if opts.MaxFiles == 0 {
+		opts.MaxFiles = defaultFileMaxFiles
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errs.Wrap(err)
+	}
+
+	buffer := thrift.NewTMemoryBuffer()
+	return &FileTransport{
+		log:      log,
+		dir:      dir,
+		opts:     opts,
+		buffer:   buffer,
+		protocol: thrift.NewTBinaryProtocolConf(buffer, nil),
+	}, nil
+}
+
+// Send appends the batch to the current file.
+func (t *FileTransport) Send(ctx context.Context, batch *jaeger.Batch) error {
+	record, err := t.encode(ctx, batch)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+
+	now := time.Now()
+	if t.file != nil && (t.size+int64(len(record)) > t.opts.MaxFileSize ||
+		t.opts.MaxFileAge > 0 && now.Sub(t.opened) >= t.opts.MaxFileAge) {
+		if err := t.closeFile(); err != nil {
+			return errs.Wrap(err)
+		}
+		mon.Counter("jaeger_file_rotated").Inc(1)
+	}
+	if t.file == nil {
+		if err := t.create(now); err != nil {
+			return errs.Wrap(err)
+		}
+	}
+
+	if _, err := t.file.Write(record); err != nil {
+		t.discardPartialWrite()
+		return errs.Wrap(err)
+	}
+	t.size += int64(len(record))
+	return nil
+}
+
+// discardPartialWrite removes what a failed write left of its record from the
+// current file, so that the file can still be read. The file is closed if that
+// fails, and the next batch goes into a new file.
+func (t *FileTransport) discardPartialWrite() {
+	err := t.file.Truncate(t.size)
+	if err == nil {
+		_, err = t.file.Seek(t.size, io.SeekStart)
+	}
+	if err == nil {
+		return
+	}
+
+	t.log.Debug("failed to discard partial trace record", zap.String("file", t.file.Name()), zap.Error(err))
+	if err := t.closeFile(); err != nil {
+		t.log.Debug("failed to close trace file", zap.Error(err))
+	}
+}
+
+// encode returns the record of the batch.
+func (t *FileTransport) encode(ctx context.Context, batch *jaeger.Batch) ([]byte, error) {
+	if t.opts.Format == FileFormatJSON {
+		data, err := json.Marshal(batch)
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	}
+
+	t.buffer.Reset()
+	var length [4]byte
+	_, _ = t.buffer.Write(length[:])
+	if err := batch.Write(ctx, t.protocol); err != nil {
+		return nil, err
+	}
+	record := t.buffer.Bytes()
+	binary.BigEndian.PutUint32(record, uint32(len(record)-len(length)))
+	return record, nil
+}
+
+// create creates a new file, and removes the files which exceed the
+// retention limits.
+func (t *FileTransport) create(now time.Time) error {
+	for {
+		name := traceFilePrefix + now.UTC().Format(traceFileTimeLayout) + t.opts.Format.extension()
+		file, err := os.OpenFile(filepath.Join(t.dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			now = now.Add(time.Nanosecond)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		t.file, t.size, t.opened = file, 0, now
+		break
+	}
+
+	t.removeOld(now)
+	return nil
+}
+
+// removeOld removes the files which exceed the retention limits.
+func (t *FileTransport) removeOld(now time.Time) {
+	files, err := TraceFiles(t.dir)
+	if err != nil {
+		t.log.Debug("failed to list trace files", zap.Error(err))
+		return
+	}
+
+	for i, file := range files {
+		if file == t.file.Name() {
+			continue
+		}
+		remove := t.opts.MaxFiles > 0 && len(files)-i > t.opts.MaxFiles
+		if !remove && t.opts.Retention > 0 {
+			info, err := os.Stat(file)
+			remove = err == nil && now.Sub(info.ModTime()) > t.opts.Retention
+		}
+		if !remove {
+			continue
+		}
+		if err := os.Remove(file); err != nil {
+			t.log.Debug("failed to remove trace file", zap.String("file", file), zap.Error(err))
+			continue
+		}
+		mon.Counter("jaeger_file_removed").Inc(1)
+	}
+}
+
+func (t *FileTransport) closeFile() error {
+	err := t.file.Close()
+	t.file = nil
+	return err
+}
+
+// Close closes the current file.
+func (t *FileTransport) Close() {
+	if t.file == nil {
+		return
+	}
+	if err := t.closeFile(); err != nil {
+		t.log.Debug("failed to close trace file", zap.Error(err))
+	}
+}
+
+// TraceFiles returns the trace files written by a FileTransport to dir,
+// oldest first.
+func TraceFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, traceFilePrefix) {
+			continue
+		}
+		if ext := filepath.Ext(name); ext != FileFormatThrift.extension() && ext != FileFormatJSON.extension() {
+			continue
+		}
+		files = append(files, filepath.Join(dir, name))
+	}
+	// the names start with the time the files were created.
+	sort.Strings(files)
+	return files, nil
+}
+
+// ReadTraceFile calls fn with every batch in the trace file at path. The
+// format of the file is detected from its extension.
+func ReadTraceFile(ctx context.Context, path string, fn func(batch *jaeger.Batch) error) (err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, file.Close()) }()
+
+	reader := bufio.NewReader(file)
+	if filepath.Ext(path) == FileFormatJSON.extension() {
+		decoder := json.NewDecoder(reader)
+		for {
+			batch := &jaeger.Batch{}
+			if err := decoder.Decode(batch); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return errs.Wrap(err)
+			}
+			if err := fn(batch); err != nil {
+				return err
+			}
+		}
+	}
+
+	buffer := thrift.NewTMemoryBuffer()
+	protocol := thrift.NewTBinaryProtocolConf(buffer, nil)
+	var length [4]byte
+	for {
+		if _, err := io.ReadFull(reader, length[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return errs.Wrap(err)
+		}
+		size := binary.BigEndian.Uint32(length[:])
+		if size > maxTraceFileRecordSize {
+			return errs.New("record of %d bytes is too large", size)
+		}
+
+		buffer.Reset()
+		if _, err := io.CopyN(buffer, reader, int64(size)); err != nil {
+			return errs.Wrap(err)
+		}
+		batch := &jaeger.Batch{}
+		if err := batch.Read(ctx, protocol); err != nil {
+			return errs.Wrap(err)
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+	}
+}
+
+// ReplayFile sends the batches in the trace file at path with transport, and
+// returns the number of spans sent. Batches which are too large for the
+// transport are split.
+func ReplayFile(ctx context.Context, transport Transport, path string) (spans int, err error) {
+	var send func(batch *jaeger.Batch) error
+	send = func(batch *jaeger.Batch) error {
+		err := transport.Send(ctx, batch)
+		if errors.Is(err, ErrBatchTooLarge) && len(batch.Spans) > 1 {
+			half := len(batch.Spans) / 2
+			first, second := *batch, *batch
+			first.Spans, second.Spans = batch.Spans[:half], batch.Spans[half:]
+			return errs.Combine(send(&first), send(&second))
+		}
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		spans += len(batch.Spans)
+		return nil
+	}
+
+	err = ReadTraceFile(ctx, path, send)
+	return spans, err
+}
@@ -0,0 +1,200 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"storj.io/common/testcontext"
+	"storj.io/monkit-jaeger/gen-go/jaeger"
+)
+
+func newFileTestBatch(spans int) *jaeger.Batch {
+	batch := &jaeger.Batch{Process: &jaeger.Process{ServiceName: "storagenode"}}
+	for i := 0; i < spans; i++ {
+		span := newTestSpan("piecestore")
+		span.Tags = NewJaegerTags([]Tag{{Key: "bytes", Value: i}})
+		batch.Spans = append(batch.Spans, span)
+	}
+	return batch
+}
+
+func TestFileTransport(t *testing.T) {
+	for _, format := range []FileFormat{FileFormatThrift, FileFormatJSON} {
+		format := format
+		t.Run(string(format), func(t *testing.T) {
+			ctx := testcontext.New(t)
+			dir := filepath.Join(ctx.Dir(), "traces")
+
+			transport, err := OpenFileTransport(ctx, zaptest.NewLogger(t), "file://"+filepath.ToSlash(dir), FileTransportOptions{
+				Format:      format,
+				MaxFileSize: 1,
+				MaxFiles:    3,
+			})
+			require.NoError(t, err)
+
+			// every batch goes into its own file, and only the last three
+			// files are kept.
+			var sent []*jaeger.Batch
+			for i := 0; i < 5; i++ {
+				batch := newFileTestBatch(i + 1)
+				require.NoError(t, transport.Send(ctx, batch))
+				sent = append(sent, batch)
+			}
+			transport.Close()
+
+			files, err := TraceFiles(dir)
+			require.NoError(t, err)
+			require.Len(t, files, 3)
+
+			for i, file := range files {
+				var read []*jaeger.Batch
+				require.NoError(t, ReadTraceFile(ctx, file, func(batch *jaeger.Batch) error {
+					read = append(read, batch)
+					return nil
+				}))
+				require.Len(t, read, 1)
+				require.Equal(t, sent[i+2].Process.ServiceName, read[0].Process.ServiceName)
+				require.Len(t, read[0].Spans, len(sent[i+2].Spans))
+				for j, span := range read[0].Spans {
+					require.Equal(t, sent[i+2].Spans[j].SpanId, span.SpanId)
+					require.Equal(t, sent[i+2].Spans[j].Tags[0].GetVLong(), span.Tags[0].GetVLong())
+				}
+			}
+		})
+	}
+}
+
+func TestFileTransportRotation(t *testing.T) {
+	ctx := testcontext.New(t)
+	dir := ctx.Dir()
+
+	transport, err := OpenFileTransport(ctx, zaptest.NewLogger(t), dir, FileTransportOptions{
+		MaxFileAge: time.Hour,
+		MaxFiles:   -1,
+		Retention:  time.Hour,
+	})
+	require.NoError(t, err)
+	defer transport.Close()
+
+	// batches are appended to the current file until it gets too old.
+	require.NoError(t, transport.Send(ctx, newFileTestBatch(1)))
+	require.NoError(t, transport.Send(ctx, newFileTestBatch(1)))
+	files, err := TraceFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	transport.opened = transport.opened.Add(-2 * time.Hour)
+	require.NoError(t, transport.Send(ctx, newFileTestBatch(1)))
+	files, err = TraceFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	// files which weren't written for longer than the retention are removed.
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(files[0], old, old))
+	transport.opened = transport.opened.Add(-2 * time.Hour)
+	require.NoError(t, transport.Send(ctx, newFileTestBatch(1)))
+	remaining, err := TraceFiles(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{files[1], transport.file.Name()}, remaining)
+
+	_, err = OpenFileTransport(ctx, zaptest.NewLogger(t), dir, FileTransportOptions{Format: "xml"})
+	require.Error(t, err)
+}
+
+// shortWriteFile writes only half of the next record, and fails.
+type shortWriteFile struct {
+	*os.File
+	short bool
+}
+
+func (file *shortWriteFile) Write(data []byte) (int, error) {
+	if !file.short {
+		return file.File.Write(data)
+	}
+	file.short = false
+	n, err := file.File.Write(data[:len(data)/2])
+	if err != nil {
+		return n, err
+	}
+	return n, io.ErrShortWrite
+}
+
+func TestFileTransportShortWrite(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	transport, err := OpenFileTransport(ctx, zaptest.NewLogger(t), ctx.Dir(), FileTransportOptions{})
+	require.NoError(t, err)
+	require.NoError(t, transport.Send(ctx, newFileTestBatch(1)))
+	size := transport.size
+
+	// the partial record is removed, and the next record follows the last
+	// complete one.
+	transport.file = &shortWriteFile{File: transport.file.(*os.File), short: true}
+	require.Error(t, transport.Send(ctx, newFileTestBatch(2)))
+	require.Equal(t, size, transport.size)
+	require.NoError(t, transport.Send(ctx, newFileTestBatch(3)))
+	transport.Close()
+
+	files, err := TraceFiles(ctx.Dir())
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	info, err := os.Stat(files[0])
+	require.NoError(t, err)
+	require.Equal(t, transport.size, info.Size())
+
+	var spans []int
+	require.NoError(t, ReadTraceFile(ctx, files[0], func(batch *jaeger.Batch) error {
+		spans = append(spans, len(batch.Spans))
+		return nil
+	}))
+	require.Equal(t, []int{1, 3}, spans)
+}
+
+func TestReplayFile(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	transport, err := OpenFileTransport(ctx, zaptest.NewLogger(t), ctx.Dir(), FileTransportOptions{})
+	require.NoError(t, err)
+	require.NoError(t, transport.Send(ctx, newFileTestBatch(5)))
+	require.NoError(t, transport.Send(ctx, newFileTestBatch(2)))
+	transport.Close()
+
+	files, err := TraceFiles(ctx.Dir())
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	// batches which are too large for the transport are split.
+	recording := &recordingTransport{maxSpans: 2}
+	spans, err := ReplayFile(ctx, recording, files[0])
+	require.NoError(t, err)
+	require.Equal(t, 7, spans)
+	require.Len(t, recording.batches, 4)
+	for _, batch := range recording.batches {
+		require.Equal(t, "storagenode", batch.Process.ServiceName)
+	}
+
+	// a truncated file replays the complete batches.
+	info, err := os.Stat(files[0])
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(files[0], info.Size()-1))
+	spans, err = ReplayFile(ctx, &recordingTransport{}, files[0])
+	require.Error(t, err)
+	require.Equal(t, 5, spans)
+
+	collector, err := NewThriftCollector(zaptest.NewLogger(t), "file://"+filepath.ToSlash(ctx.Dir()), "test", nil, 0, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, fileTransportType, collector.transportType)
+	opened, err := collector.OpenTransport(ctx)
+	require.NoError(t, err)
+	opened.Close()
+}
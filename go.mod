@@ -4,13 +4,16 @@ go 1.18
 
 require (
 	github.com/apache/thrift v0.16.0
+	github.com/klauspost/compress v1.17.0
 	github.com/spacemonkeygo/monkit/v3 v3.0.18
 	github.com/stretchr/testify v1.7.0
 	github.com/zeebo/errs v1.3.0
 	github.com/zeebo/mwc v0.0.4
 	go.uber.org/zap v1.14.1
 	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
+	google.golang.org/protobuf v1.27.1
 	storj.io/common v0.0.0-20220719163320-cd2ef8e1b9b0
+	storj.io/drpc v0.0.32
 )
 
 require (
@@ -22,5 +25,4 @@ require (
 	golang.org/x/lint v0.0.0-20200302205851-738671d3881b // indirect
 	golang.org/x/net v0.0.0-20220526153639-5463443f8c37 // indirect
 	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
-	storj.io/drpc v0.0.32 // indirect
 )
@@ -5,70 +5,347 @@ package jaeger
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"io"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/klauspost/compress/zstd"
 	"github.com/zeebo/errs"
 	"go.uber.org/zap"
 
 	"storj.io/monkit-jaeger/gen-go/jaeger"
 )
 
+// HTTPCompression is the content encoding of the requests of an
+// HTTPTransport.
+type HTTPCompression string
+
+const (
+	// HTTPCompressionNone sends uncompressed requests.
+	HTTPCompressionNone HTTPCompression = ""
+	// HTTPCompressionGzip sends gzip compressed requests.
+	HTTPCompressionGzip HTTPCompression = "gzip"
+	// HTTPCompressionZstd sends zstd compressed requests.
+	HTTPCompressionZstd HTTPCompression = "zstd"
+)
+
+// defaultHTTPUserAgent is the user agent of HTTPTransport requests if none is
+// configured.
+const defaultHTTPUserAgent = "monkit-jaeger"
+
+// HTTPTransportOptions configures an HTTPTransport.
+type HTTPTransportOptions struct {
+	// Client sends the requests. If nil, a client with RoundTripper is used.
+	Client *http.Client
+	// RoundTripper sends the requests if Client is nil. If nil, a clone of
+	// http.DefaultTransport with TLSConfig is used.
+	RoundTripper http.RoundTripper
+	// TLSConfig configures TLS, including client certificates, if neither
+	// Client nor RoundTripper is set. See NewClientTLSConfig.
+	TLSConfig *tls.Config
+
+	// Headers are added to every request.
+	Headers http.Header
+	// HeaderFunc is called for every request to add dynamic headers.
+	HeaderFunc func(ctx context.Context, header http.Header) error
+	// BearerToken returns the token for the Authorization header of every
+	// request.
+	BearerToken func(ctx context.Context) (string, error)
+	// Username and Password are sent with basic auth, if Username is set.
+	Username string
+	Password string
+
+	// Timeout limits the duration of every request. No limit if zero.
+	Timeout time.Duration
+	// UserAgent is the user agent of the requests. Defaults to
+	// "monkit-jaeger".
+	UserAgent string
+	// Compression is the content encoding of the requests. If the receiver
+	// rejects it with 415 Unsupported Media Type, the transport falls back to
+	// uncompressed requests.
+	Compression HTTPCompression
+	// MaxRequestSize limits the size of the request bodies, after
+	// compression. Send returns ErrBatchTooLarge for larger batches. No limit
+	// if zero.
+	MaxRequestSize int
+}
+
+// NewClientTLSConfig returns a TLS config which authenticates with the client
+// certificate in certFile and keyFile, if set, and trusts the certificate
+// authorities in caFile, if set, instead of the system ones.
+func NewClientTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, errs.Wrap(err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, errs.Wrap(err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errs.New("no certificates found in %q", caFile)
+		}
+	}
+
+	return config, nil
+}
+
 // HTTPTransport sends Jaeger spans via HTTP.
 type HTTPTransport struct {
-	log      *zap.Logger
-	addr     string
-	protocol *thrift.TBinaryProtocol
-	buffer   *thrift.TMemoryBuffer
+	log         *zap.Logger
+	addr        string
+	contentType string
+	marshal     func(ctx context.Context, batch *jaeger.Batch) ([]byte, error)
+	opts        HTTPTransportOptions
+	client      *http.Client
+	ownsIdle    bool // whether the idle connections of client are ours to close
+
+	compression HTTPCompression // the negotiated content encoding
+	body        bytes.Buffer    // the gzip compressed request body
+	gzip        *gzip.Writer
+	zstdBody    []byte // the zstd compressed request body
+	zstd        *zstd.Encoder
 }
 
 var _ Transport = &HTTPTransport{}
 
 // OpenHTTPTransport creates a new HTTP transport.
 func OpenHTTPTransport(ctx context.Context, log *zap.Logger, agentAddr string) (*HTTPTransport, error) {
+	return OpenHTTPTransportWithOptions(ctx, log, agentAddr, HTTPTransportOptions{})
+}
+
+// OpenHTTPTransportWithOptions creates a new HTTP transport configured with
+// opts.
+func OpenHTTPTransportWithOptions(ctx context.Context, log *zap.Logger, agentAddr string, opts HTTPTransportOptions) (*HTTPTransport, error) {
+	buffer := thrift.NewTMemoryBuffer()
+	protocol := thrift.NewTBinaryProtocolConf(buffer, nil)
+	return openHTTPTransport(log, agentAddr, opts, "application/x-thrift", func(ctx context.Context, batch *jaeger.Batch) ([]byte, error) {
+		buffer.Reset()
+		if err := batch.Write(ctx, protocol); err != nil {
+			return nil, err
+		}
+		return buffer.Bytes(), nil
+	})
+}
+
+// openHTTPTransport creates a new HTTP transport which posts the batches
+// encoded with marshal.
+func openHTTPTransport(log *zap.Logger, addr string, opts HTTPTransportOptions, contentType string,
+	marshal func(ctx context.Context, batch *jaeger.Batch) ([]byte, error)) (*HTTPTransport, error) {
+	switch opts.Compression {
+	case HTTPCompressionNone, HTTPCompressionGzip, HTTPCompressionZstd:
+	default:
+		return nil, errs.New("unsupported compression %q", opts.Compression)
+	}
+
+	client, ownsIdle := opts.Client, opts.Client == nil && opts.RoundTripper == nil
+	if client == nil {
+		roundTripper := opts.RoundTripper
+		if roundTripper == nil {
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			if opts.TLSConfig != nil {
+				transport.TLSClientConfig = opts.TLSConfig.Clone()
+			}
+			roundTripper = transport
+		}
+		client = &http.Client{Transport: roundTripper}
+	}
+
+	if opts.UserAgent == "" {
+		opts.UserAgent = defaultHTTPUserAgent
+	}
 
-	t := thrift.NewTMemoryBuffer()
-	p := thrift.NewTBinaryProtocolConf(t, nil)
 	return &HTTPTransport{
-		log:      log,
-		addr:     agentAddr,
-		protocol: p,
-		buffer:   t,
+		log:         log,
+		addr:        addr,
+		contentType: contentType,
+		marshal:     marshal,
+		opts:        opts,
+		client:      client,
+		ownsIdle:    ownsIdle,
+		compression: opts.Compression,
 	}, nil
-
 }
 
 // Send sends out the Jaeger spans.
 func (u *HTTPTransport) Send(ctx context.Context, batch *jaeger.Batch) error {
-	u.buffer.Reset()
-	err := batch.Write(ctx, u.protocol)
+	data, err := u.marshal(ctx, batch)
 	if err != nil {
 		return errs.Wrap(err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.addr, bytes.NewReader(u.buffer.Bytes()))
+	if u.opts.Timeout > 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, u.opts.Timeout)
+		defer cancel()
+	}
+
+	compression := u.compression
+	err = u.post(ctx, batch, data, compression)
+	if compression != HTTPCompressionNone && errors.Is(err, errUnsupportedEncoding) {
+		// the receiver doesn't support the encoding, so stick to uncompressed
+		// requests from now on.
+		u.log.Debug("falling back to uncompressed requests", zap.String("compression", string(compression)))
+		mon.Counter("jaeger_http_compression_rejected").Inc(1)
+		u.compression = HTTPCompressionNone
+		err = u.post(ctx, batch, data, HTTPCompressionNone)
+	}
+	return err
+}
+
+// errUnsupportedEncoding is returned by post when the receiver rejected the
+// content encoding of the request.
+var errUnsupportedEncoding = errors.New("unsupported content encoding")
 
+// post sends the encoded batch with the given compression.
+func (u *HTTPTransport) post(ctx context.Context, batch *jaeger.Batch, data []byte, compression HTTPCompression) error {
+	body, err := u.encodeBody(data, compression)
 	if err != nil {
 		return errs.Wrap(err)
 	}
-	req.Header.Add("Content-Type", "application/x-thrift")
-	resp, err := http.DefaultClient.Do(req)
+	if u.opts.MaxRequestSize > 0 && len(body) > u.opts.MaxRequestSize {
+		return errs.New("%w: data size: %d, max size: %d, spans: %d",
+			ErrBatchTooLarge, len(body), u.opts.MaxRequestSize, len(batch.Spans))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.addr, bytes.NewReader(body))
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	req.Header.Add("Content-Type", u.contentType)
+	if compression != HTTPCompressionNone {
+		req.Header.Set("Content-Encoding", string(compression))
+	}
+	if err := u.setHeaders(ctx, req); err != nil {
+		return errs.Wrap(err)
+	}
+
+	resp, err := u.client.Do(req)
 	if err != nil {
 		return errs.Wrap(err)
 	}
 	defer func() {
 		_ = resp.Body.Close()
 	}()
+	if resp.StatusCode == http.StatusUnsupportedMediaType && compression != HTTPCompressionNone {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return errUnsupportedEncoding
+	}
 	if resp.StatusCode >= 400 {
 		raw, _ := io.ReadAll(resp.Body)
-		return errs.New("Error on posting data to jaeger. HTTP %s: %s", resp.Status, string(raw))
+		err := errs.New("Error on posting data to jaeger. HTTP %s: %s", resp.Status, string(raw))
+		return classifyHTTPError(resp, err)
+	}
+	return nil
+}
+
+// encodeBody returns the request body for the encoded data.
+func (u *HTTPTransport) encodeBody(data []byte, compression HTTPCompression) ([]byte, error) {
+	var body []byte
+	switch compression {
+	case HTTPCompressionGzip:
+		u.body.Reset()
+		if u.gzip == nil {
+			u.gzip = gzip.NewWriter(&u.body)
+		} else {
+			u.gzip.Reset(&u.body)
+		}
+		if _, err := u.gzip.Write(data); err != nil {
+			return nil, err
+		}
+		if err := u.gzip.Close(); err != nil {
+			return nil, err
+		}
+		body = u.body.Bytes()
+	case HTTPCompressionZstd:
+		if u.zstd == nil {
+			encoder, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+			if err != nil {
+				return nil, err
+			}
+			u.zstd = encoder
+		}
+		u.zstdBody = u.zstd.EncodeAll(data, u.zstdBody[:0])
+		body = u.zstdBody
+	default:
+		return data, nil
+	}
+
+	mon.Counter("jaeger_http_bytes_uncompressed").Inc(int64(len(data)))
+	mon.Counter("jaeger_http_bytes_compressed").Inc(int64(len(body)))
+	return body, nil
+}
+
+// setHeaders adds the configured headers to the request.
+func (u *HTTPTransport) setHeaders(ctx context.Context, req *http.Request) error {
+	req.Header.Set("User-Agent", u.opts.UserAgent)
+
+	for key, values := range u.opts.Headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	if u.opts.Username != "" {
+		req.SetBasicAuth(u.opts.Username, u.opts.Password)
+	}
+
+	if u.opts.BearerToken != nil {
+		token, err := u.opts.BearerToken(ctx)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	if u.opts.HeaderFunc != nil {
+		if err := u.opts.HeaderFunc(ctx, req.Header); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
 // Close closes the transport.
 func (u *HTTPTransport) Close() {
+	if u.ownsIdle {
+		u.client.CloseIdleConnections()
+	}
+	if u.zstd != nil {
+		_ = u.zstd.Close()
+	}
+}
 
+// classifyHTTPError marks errors for client error responses as permanent, and
+// adds the delay the server asked for to retryable ones. Batches rejected as
+// too large fail with ErrBatchTooLarge, so that they are split.
+func classifyHTTPError(resp *http.Response, err error) error {
+	switch resp.StatusCode {
+	case http.StatusRequestEntityTooLarge:
+		return errs.New("%w: %v", ErrBatchTooLarge, err)
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+	default:
+		if resp.StatusCode < 500 {
+			return ErrPermanent.Wrap(err)
+		}
+	}
+	if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		return &RetryAfterError{Delay: delay, Err: err}
+	}
+	return err
 }
@@ -0,0 +1,353 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"storj.io/common/testcontext"
+	"storj.io/monkit-jaeger/gen-go/jaeger"
+)
+
+// writeClientCert writes a self-signed client certificate and its key into
+// dir and returns their paths.
+func writeClientCert(t *testing.T, dir string) (certFile, keyFile string, cert *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, "client.crt")
+	keyFile = filepath.Join(dir, "client.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile, cert
+}
+
+func TestHTTPTransportOptions(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	certFile, keyFile, clientCert := writeClientCert(t, ctx.Dir())
+
+	requests := make(chan *http.Request, 1)
+	batches := make(chan *jaeger.Batch, 1)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		batch := readBatch(t, r)
+
+		requests <- r
+		batches <- batch
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: x509.NewCertPool()}
+	server.TLS.ClientCAs.AddCert(clientCert)
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(ctx.Dir(), "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+	tlsConfig, err := NewClientTLSConfig(certFile, keyFile, caFile)
+	require.NoError(t, err)
+
+	transport, err := OpenHTTPTransportWithOptions(ctx, zaptest.NewLogger(t), server.URL, HTTPTransportOptions{
+		TLSConfig: tlsConfig,
+		Headers:   http.Header{"X-Static": {"static"}},
+		HeaderFunc: func(ctx context.Context, header http.Header) error {
+			header.Set("X-Dynamic", "dynamic")
+			return nil
+		},
+		BearerToken: func(ctx context.Context) (string, error) { return "token", nil },
+		Timeout:     time.Minute,
+		UserAgent:   "test-agent",
+		Compression: HTTPCompressionGzip,
+	})
+	require.NoError(t, err)
+	defer transport.Close()
+
+	span := newTestSpan("sent")
+	require.NoError(t, transport.Send(ctx, &jaeger.Batch{
+		Process: &jaeger.Process{ServiceName: "test"},
+		Spans:   []*jaeger.Span{span},
+	}))
+
+	r := <-requests
+	require.Equal(t, "static", r.Header.Get("X-Static"))
+	require.Equal(t, "dynamic", r.Header.Get("X-Dynamic"))
+	require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+	require.Equal(t, "test-agent", r.Header.Get("User-Agent"))
+	require.Equal(t, "application/x-thrift", r.Header.Get("Content-Type"))
+
+	batch := <-batches
+	require.Len(t, batch.Spans, 1)
+	require.Equal(t, span.SpanId, batch.Spans[0].SpanId)
+
+	// the server rejects clients without certificates.
+	transport, err = OpenHTTPTransportWithOptions(ctx, zaptest.NewLogger(t), server.URL, HTTPTransportOptions{
+		TLSConfig: &tls.Config{RootCAs: tlsConfig.RootCAs},
+	})
+	require.NoError(t, err)
+	require.Error(t, transport.Send(ctx, &jaeger.Batch{Process: &jaeger.Process{ServiceName: "test"}}))
+}
+
+func TestHTTPTransportBasicAuthAndTimeout(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	release := make(chan struct{})
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("X-Slow") != "" {
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	batch := &jaeger.Batch{Process: &jaeger.Process{ServiceName: "test"}}
+
+	transport, err := OpenHTTPTransportWithOptions(ctx, zaptest.NewLogger(t), server.URL, HTTPTransportOptions{
+		Client:   server.Client(),
+		Username: "user",
+		Password: "secret",
+	})
+	require.NoError(t, err)
+	require.NoError(t, transport.Send(ctx, batch))
+
+	// wrong credentials are not worth retrying.
+	transport, err = OpenHTTPTransportWithOptions(ctx, zaptest.NewLogger(t), server.URL, HTTPTransportOptions{
+		RoundTripper: server.Client().Transport,
+		Username:     "user",
+	})
+	require.NoError(t, err)
+	require.True(t, ErrPermanent.Has(transport.Send(ctx, batch)))
+
+	transport, err = OpenHTTPTransportWithOptions(ctx, zaptest.NewLogger(t), server.URL, HTTPTransportOptions{
+		Client:   server.Client(),
+		Username: "user",
+		Password: "secret",
+		Headers:  http.Header{"X-Slow": {"true"}},
+		Timeout:  10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, transport.Send(ctx, batch), context.DeadlineExceeded)
+
+	_, err = OpenHTTPTransportWithOptions(ctx, zaptest.NewLogger(t), server.URL, HTTPTransportOptions{
+		Compression: "brotli",
+	})
+	require.Error(t, err)
+}
+
+// readBatch reads the thrift batch from the body of the request, which is
+// compressed with gzip or zstd if the request says so.
+func readBatch(t *testing.T, r *http.Request) *jaeger.Batch {
+	body := io.Reader(r.Body)
+	switch r.Header.Get("Content-Encoding") {
+	case "gzip":
+		gz, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		body = gz
+	case "zstd":
+		decoder, err := zstd.NewReader(r.Body)
+		require.NoError(t, err)
+		defer decoder.Close()
+		body = decoder
+	}
+
+	batch := &jaeger.Batch{}
+	protocol := thrift.NewTBinaryProtocolConf(thrift.NewStreamTransportR(body), nil)
+	require.NoError(t, batch.Read(r.Context(), protocol))
+	return batch
+}
+
+func TestHTTPTransportCompression(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	var mu sync.Mutex
+	var encodings []string
+	var spans int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		encoding := r.Header.Get("Content-Encoding")
+		encodings = append(encodings, encoding)
+		if encoding == "gzip" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		spans += len(readBatch(t, r).Spans)
+	}))
+	defer server.Close()
+
+	batch := &jaeger.Batch{Process: &jaeger.Process{ServiceName: "test"}}
+	for i := 0; i < 20; i++ {
+		batch.Spans = append(batch.Spans, newTestSpan("compressible"))
+	}
+
+	uncompressed := mon.Counter("jaeger_http_bytes_uncompressed").Current()
+	compressed := mon.Counter("jaeger_http_bytes_compressed").Current()
+
+	transport, err := OpenHTTPTransportWithOptions(ctx, zaptest.NewLogger(t), server.URL, HTTPTransportOptions{
+		Compression: HTTPCompressionZstd,
+	})
+	require.NoError(t, err)
+	require.NoError(t, transport.Send(ctx, batch))
+	transport.Close()
+
+	uncompressed = mon.Counter("jaeger_http_bytes_uncompressed").Current() - uncompressed
+	compressed = mon.Counter("jaeger_http_bytes_compressed").Current() - compressed
+	require.Greater(t, compressed, int64(0))
+	require.Less(t, compressed, uncompressed)
+
+	// the transport falls back to uncompressed requests when the server
+	// rejects the encoding.
+	transport, err = OpenHTTPTransportWithOptions(ctx, zaptest.NewLogger(t), server.URL, HTTPTransportOptions{
+		Compression: HTTPCompressionGzip,
+	})
+	require.NoError(t, err)
+	require.NoError(t, transport.Send(ctx, batch))
+	require.NoError(t, transport.Send(ctx, batch))
+	transport.Close()
+
+	mu.Lock()
+	require.Equal(t, []string{"zstd", "gzip", "", ""}, encodings)
+	require.Equal(t, 3*len(batch.Spans), spans)
+	mu.Unlock()
+
+	// the request size is limited after compression.
+	transport, err = OpenHTTPTransportWithOptions(ctx, zaptest.NewLogger(t), server.URL, HTTPTransportOptions{
+		Compression:    HTTPCompressionZstd,
+		MaxRequestSize: int(compressed) - 1,
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, transport.Send(ctx, batch), ErrBatchTooLarge)
+	transport.Close()
+}
+
+func TestThriftCollectorCompressedBatches(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	var mu sync.Mutex
+	var requests, spans int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(body), 2000)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		requests++
+		spans += len(readBatch(t, r).Spans)
+	}))
+	defer server.Close()
+
+	collector, err := NewThriftCollector(zaptest.NewLogger(t), server.URL, "test", nil, 2000, 0, time.Hour)
+	require.NoError(t, err)
+	collector.SetRetryPolicy(RetryPolicy{Attempts: 1})
+	collector.SetHTTPTransportOptions(HTTPTransportOptions{Compression: HTTPCompressionZstd})
+
+	transport, err := OpenHTTPTransportWithOptions(ctx, zaptest.NewLogger(t), server.URL, collector.httpOptions)
+	require.NoError(t, err)
+	defer transport.Close()
+
+	// the spans take up more than twice the packet size when uncompressed.
+	total := 0
+	for i := 0; total < 2*collector.maxSpanBytes; i++ {
+		span := newTestSpan("a-long-and-very-compressible-operation-name")
+		size, err := calculateThriftSize(span, collector.spanSizeBuffer, collector.spanSizeProtocol)
+		require.NoError(t, err)
+		total += size
+		require.NoError(t, collector.handleSpan(ctx, span, transport))
+	}
+	require.NoError(t, collector.Send(ctx, transport))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, 1, requests)
+	require.Greater(t, spans, 1)
+}
+
+func TestThriftCollectorSplitsRejectedBatches(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	var mu sync.Mutex
+	var sizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// the server only accepts batches with a single span.
+		spans := len(readBatch(t, r).Spans)
+		sizes = append(sizes, spans)
+		if spans > 1 {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		}
+	}))
+	defer server.Close()
+
+	collector, err := NewThriftCollector(zaptest.NewLogger(t), server.URL, "test", nil, 0, 0, time.Hour)
+	require.NoError(t, err)
+
+	transport, err := OpenHTTPTransportWithOptions(ctx, zaptest.NewLogger(t), server.URL, collector.httpOptions)
+	require.NoError(t, err)
+	defer transport.Close()
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, collector.handleSpan(ctx, newTestSpan("split"), transport))
+	}
+	require.NoError(t, collector.Send(ctx, transport))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []int{2, 1, 1}, sizes)
+	require.Empty(t, collector.retries)
+	require.Zero(t, collector.clientStats().FailedToEmitSpans)
+}
@@ -0,0 +1,199 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package httptracing implements tracing for net/http servers and clients.
+package httptracing
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+
+	jaeger "storj.io/monkit-jaeger"
+)
+
+// Error is the error class for failed HTTP requests.
+var Error = errs.Class("httptracing")
+
+const (
+	// SpanKindTag is the tag that distinguishes server and client spans.
+	SpanKindTag = jaeger.SpanKindTag
+	// MethodTag is the tag with the HTTP method.
+	MethodTag = "http.method"
+	// RouteTag is the tag with the route of the request.
+	RouteTag = "http.route"
+	// StatusCodeTag is the tag with the HTTP response status code.
+	StatusCodeTag = "http.status_code"
+)
+
+// Options configures the tracing Handler and Transport.
+type Options struct {
+	// Propagators are the formats to read and write the trace information.
+	// Defaults to the jaeger.RemoteInfoPropagator.
+	Propagators []jaeger.Propagator
+
+	// Route returns the route of a request, e.g. "/buckets/{bucket}". It
+	// shouldn't contain user data. If nil, no route tag is added.
+	Route func(*http.Request) string
+}
+
+func (opts Options) inject(ctx context.Context, header http.Header) {
+	if len(opts.Propagators) == 0 {
+		jaeger.InjectRemoteInfoHeader(ctx, header)
+		return
+	}
+	jaeger.CompositePropagator(opts.Propagators).Inject(ctx, jaeger.HeaderCarrier(header))
+}
+
+// Handler implements http.Handler and traces every request.
+type Handler struct {
+	f       *monkit.Func
+	handler http.Handler
+	opts    Options
+}
+
+// NewHandler returns a new instance of Handler, which starts a span of f for
+// every request to handler. The span continues the remote trace if the
+// request contains trace information.
+func NewHandler(f *monkit.Func, handler http.Handler, opts Options) *Handler {
+	return &Handler{
+		f:       f,
+		handler: handler,
+		opts:    opts,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var err error
+	ctx := r.Context()
+
+	trace, parentID := jaeger.RemoteTraceHandler(remoteInfo(r.Header), h.opts.Propagators...)
+	if trace != nil {
+		defer h.f.RemoteTrace(&ctx, parentID, trace)(&err)
+	} else {
+		defer h.f.Task(&ctx)(&err)
+	}
+
+	span := monkit.SpanFromCtx(ctx)
+	jaeger.SetSpanKind(span, jaeger.SpanKindServer)
+	span.Annotate(MethodTag, r.Method)
+	if h.opts.Route != nil {
+		span.Annotate(RouteTag, h.opts.Route(r))
+	}
+
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	defer func() {
+		err = annotateStatus(span, sw.status)
+	}()
+
+	h.handler.ServeHTTP(sw, r.WithContext(ctx))
+}
+
+// remoteInfo converts the headers into the remote info expected by
+// jaeger.RemoteTraceHandler.
+func remoteInfo(header http.Header) map[string]string {
+	info := make(map[string]string, len(header))
+	for key, values := range header {
+		if len(values) > 0 {
+			info[strings.ToLower(key)] = values[0]
+		}
+	}
+	return info
+}
+
+// annotateStatus adds the status code tag and returns an error for server
+// errors, so they are marked as failed.
+func annotateStatus(span *monkit.Span, status int) error {
+	span.Annotate(StatusCodeTag, strconv.Itoa(status))
+	if status < http.StatusInternalServerError {
+		return nil
+	}
+	return Error.New("HTTP %d", status)
+}
+
+// statusWriter records the status code of the response.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush implements http.Flusher.
+func (w *statusWriter) Flush() {
+	if fl, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		fl.Flush()
+	}
+}
+
+// Unwrap returns the wrapped http.ResponseWriter.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Transport implements http.RoundTripper and traces every request.
+type Transport struct {
+	f         *monkit.Func
+	transport http.RoundTripper
+	opts      Options
+}
+
+// NewTransport returns a new instance of Transport, which starts a span of f
+// for every request and sends the trace information along. If transport is
+// nil, http.DefaultTransport is used.
+func NewTransport(f *monkit.Func, transport http.RoundTripper, opts Options) *Transport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Transport{
+		f:         f,
+		transport: transport,
+		opts:      opts,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var spanErr error
+	ctx := req.Context()
+	defer t.f.Task(&ctx)(&spanErr)
+
+	span := monkit.SpanFromCtx(ctx)
+	jaeger.SetSpanKind(span, jaeger.SpanKindClient)
+	span.Annotate(MethodTag, req.Method)
+	if t.opts.Route != nil {
+		span.Annotate(RouteTag, t.opts.Route(req))
+	}
+
+	// the request must not be modified, see http.RoundTripper.
+	req = req.Clone(ctx)
+	t.opts.inject(ctx, req.Header)
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		spanErr = err
+		return nil, err
+	}
+
+	// the response is still returned, only the span is marked as failed.
+	spanErr = annotateStatus(span, resp.StatusCode)
+	return resp, nil
+}
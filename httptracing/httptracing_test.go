@@ -0,0 +1,153 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package httptracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/stretchr/testify/require"
+
+	jaeger "storj.io/monkit-jaeger"
+	jaegerthrift "storj.io/monkit-jaeger/gen-go/jaeger"
+	"storj.io/monkit-jaeger/httptracing"
+)
+
+// collectorFunc is a jaeger.TraceCollector calling a func.
+type collectorFunc func(span *jaegerthrift.Span)
+
+func (f collectorFunc) Collect(span *jaegerthrift.Span) { f(span) }
+
+// recordSpans returns a collector which records the finished spans, and a
+// func which finds a recorded span by its name.
+func recordSpans(t *testing.T) (jaeger.TraceCollector, func(name string) *jaegerthrift.Span) {
+	var mu sync.Mutex
+	var spans []*jaegerthrift.Span
+	collector := collectorFunc(func(span *jaegerthrift.Span) {
+		mu.Lock()
+		defer mu.Unlock()
+		spans = append(spans, span)
+	})
+	find := func(name string) *jaegerthrift.Span {
+		mu.Lock()
+		defer mu.Unlock()
+		// the last span, since the tests make the same requests again.
+		for i := len(spans) - 1; i >= 0; i-- {
+			if spans[i].OperationName == name {
+				return spans[i]
+			}
+		}
+		require.Failf(t, "span not found", "%s", name)
+		return nil
+	}
+	return collector, find
+}
+
+func tags(span *jaegerthrift.Span) map[string]string {
+	tags := map[string]string{}
+	for _, tag := range span.Tags {
+		switch tag.VType {
+		case jaegerthrift.TagType_STRING:
+			tags[tag.Key] = tag.GetVStr()
+		case jaegerthrift.TagType_BOOL:
+			tags[tag.Key] = "bool"
+		}
+	}
+	return tags
+}
+
+func TestHandlerAndTransport(t *testing.T) {
+	r := monkit.NewRegistry()
+	collector, find := recordSpans(t)
+	defer jaeger.RegisterJaeger(r, collector, jaeger.Options{Fraction: 1})()
+
+	scope := r.ScopeNamed("test")
+	opts := httptracing.Options{
+		Propagators: []jaeger.Propagator{jaeger.TraceContextPropagator{}, jaeger.RemoteInfoPropagator{}},
+		Route: func(r *http.Request) string {
+			return "/objects/{key}"
+		},
+	}
+
+	var serverSpan *monkit.Span
+	server := httptest.NewServer(httptracing.NewHandler(scope.FuncNamed("serve"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverSpan = monkit.SpanFromCtx(r.Context())
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}), opts))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: httptracing.NewTransport(scope.FuncNamed("request"), nil, opts),
+	}
+
+	get := func(path string) int {
+		ctx := context.Background()
+		defer scope.FuncNamed("root").Task(&ctx)(nil)
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+
+	require.Equal(t, http.StatusOK, get("/objects/secret"))
+	require.NotNil(t, serverSpan)
+
+	serve := find("test.serve")
+	request := find("test.request")
+	root := find("test.root")
+
+	require.Equal(t, root.TraceIdLow, serve.TraceIdLow)
+	require.Equal(t, request.SpanId, serve.ParentSpanId)
+	require.Equal(t, root.SpanId, request.ParentSpanId)
+
+	require.Equal(t, map[string]string{
+		httptracing.SpanKindTag:   "server",
+		httptracing.MethodTag:     "GET",
+		httptracing.RouteTag:      "/objects/{key}",
+		httptracing.StatusCodeTag: "200",
+	}, tags(serve))
+	require.Equal(t, map[string]string{
+		httptracing.SpanKindTag:   "client",
+		httptracing.MethodTag:     "GET",
+		httptracing.RouteTag:      "/objects/{key}",
+		httptracing.StatusCodeTag: "200",
+	}, tags(request))
+
+	require.Equal(t, http.StatusServiceUnavailable, get("/fail"))
+
+	for _, name := range []string{"test.serve", "test.request"} {
+		span := tags(find(name))
+		require.Equal(t, "503", span[httptracing.StatusCodeTag])
+		require.Equal(t, "bool", span["error"])
+		require.Equal(t, "errored", span["status"])
+	}
+}
+
+func TestHandlerWithoutRemoteTrace(t *testing.T) {
+	r := monkit.NewRegistry()
+	collector, find := recordSpans(t)
+	defer jaeger.RegisterJaeger(r, collector, jaeger.Options{Fraction: 1})()
+
+	handler := httptracing.NewHandler(r.ScopeNamed("test").FuncNamed("serve"), http.NotFoundHandler(), httptracing.Options{})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	serve := find("test.serve")
+	require.Zero(t, serve.ParentSpanId)
+	require.Equal(t, "404", tags(serve)[httptracing.StatusCodeTag])
+	require.Equal(t, "POST", tags(serve)[httptracing.MethodTag])
+}
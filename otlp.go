@@ -0,0 +1,454 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"net/url"
+	"strings"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"storj.io/monkit-jaeger/gen-go/jaeger"
+)
+
+// OTLPSchemePrefix is the prefix of the address schemes which make a
+// ThriftCollector send OTLP traces to an OpenTelemetry collector instead of
+// an agent, e.g. "otlp+http://collector:4318".
+const OTLPSchemePrefix = "otlp+"
+
+// OTLPTracesPath is the path OTLP traces are posted to if the endpoint has no
+// path.
+const OTLPTracesPath = "/v1/traces"
+
+// OTLPEncoding is the encoding of the requests of an OTLPTransport.
+type OTLPEncoding string
+
+const (
+	// OTLPEncodingProtobuf sends binary protobuf requests.
+	OTLPEncodingProtobuf OTLPEncoding = "protobuf"
+	// OTLPEncodingJSON sends JSON requests.
+	OTLPEncodingJSON OTLPEncoding = "json"
+)
+
+// the OTLP span kinds and status codes.
+const (
+	otlpSpanKindInternal = 1
+	otlpSpanKindServer   = 2
+	otlpSpanKindClient   = 3
+	otlpSpanKindProducer = 4
+	otlpSpanKindConsumer = 5
+
+	otlpStatusCodeError = 2
+)
+
+// OTLPTransport sends spans to an OpenTelemetry collector with OTLP/HTTP.
+type OTLPTransport struct {
+	*HTTPTransport
+}
+
+var _ Transport = &OTLPTransport{}
+
+// OpenOTLPTransport creates a new transport which posts the spans in OTLP
+// format to endpoint. endpoint may have an OTLPSchemePrefix, and defaults to
+// OTLPTracesPath if it has no path. The encoding defaults to protobuf.
+func OpenOTLPTransport(ctx context.Context, log *zap.Logger, endpoint string, encoding OTLPEncoding, opts HTTPTransportOptions) (*OTLPTransport, error) {
+	parsed, err := url.Parse(strings.TrimPrefix(endpoint, OTLPSchemePrefix))
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	if parsed.Path == "" || parsed.Path == "/" {
+		parsed.Path = OTLPTracesPath
+	}
+
+	var contentType string
+	var marshal func(ctx context.Context, batch *jaeger.Batch) ([]byte, error)
+	switch encoding {
+	case OTLPEncodingProtobuf, "":
+		contentType = "application/x-protobuf"
+		marshal = func(ctx context.Context, batch *jaeger.Batch) ([]byte, error) {
+			return newOTLPRequest(batch).marshalProto(), nil
+		}
+	case OTLPEncodingJSON:
+		contentType = "application/json"
+		marshal = func(ctx context.Context, batch *jaeger.Batch) ([]byte, error) {
+			return json.Marshal(newOTLPRequest(batch))
+		}
+	default:
+		return nil, errs.New("unsupported OTLP encoding %q", encoding)
+	}
+
+	transport, err := openHTTPTransport(log, parsed.String(), opts, contentType, marshal)
+	if err != nil {
+		return nil, err
+	}
+	return &OTLPTransport{HTTPTransport: transport}, nil
+}
+
+// The OTLP trace messages, see
+// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/trace/v1/trace.proto.
+// The JSON tags follow the OTLP JSON encoding.
+type (
+	otlpRequest struct {
+		ResourceSpans []*otlpResourceSpans `json:"resourceSpans"`
+	}
+
+	otlpResourceSpans struct {
+		Resource   otlpResource      `json:"resource"`
+		ScopeSpans []*otlpScopeSpans `json:"scopeSpans"`
+	}
+
+	otlpResource struct {
+		Attributes []*otlpKeyValue `json:"attributes,omitempty"`
+	}
+
+	otlpScopeSpans struct {
+		Scope otlpScope   `json:"scope"`
+		Spans []*otlpSpan `json:"spans"`
+	}
+
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+
+	otlpSpan struct {
+		TraceID           otlpID          `json:"traceId"`
+		SpanID            otlpID          `json:"spanId"`
+		ParentSpanID      otlpID          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              int32           `json:"kind"`
+		StartTimeUnixNano uint64          `json:"startTimeUnixNano,string"`
+		EndTimeUnixNano   uint64          `json:"endTimeUnixNano,string"`
+		Attributes        []*otlpKeyValue `json:"attributes,omitempty"`
+		Events            []*otlpEvent    `json:"events,omitempty"`
+		Links             []*otlpLink     `json:"links,omitempty"`
+		Status            otlpStatus      `json:"status"`
+	}
+
+	otlpEvent struct {
+		TimeUnixNano uint64          `json:"timeUnixNano,string"`
+		Name         string          `json:"name"`
+		Attributes   []*otlpKeyValue `json:"attributes,omitempty"`
+	}
+
+	otlpLink struct {
+		TraceID otlpID `json:"traceId"`
+		SpanID  otlpID `json:"spanId"`
+	}
+
+	otlpStatus struct {
+		Message string `json:"message,omitempty"`
+		Code    int32  `json:"code,omitempty"`
+	}
+
+	otlpKeyValue struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+
+	otlpAnyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *int64   `json:"intValue,omitempty,string"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+		BytesValue  []byte   `json:"bytesValue,omitempty"`
+	}
+)
+
+// otlpID is a trace or span id, which is hex encoded in JSON.
+type otlpID []byte
+
+// MarshalJSON implements json.Marshaler.
+func (id otlpID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(id))
+}
+
+func newOTLPTraceID(high, low int64) otlpID {
+	id := make(otlpID, 16)
+	binary.BigEndian.PutUint64(id[:8], uint64(high))
+	binary.BigEndian.PutUint64(id[8:], uint64(low))
+	return id
+}
+
+func newOTLPSpanID(id int64) otlpID {
+	spanID := make(otlpID, 8)
+	binary.BigEndian.PutUint64(spanID, uint64(id))
+	return spanID
+}
+
+// newOTLPRequest converts the batch to an OTLP export request.
+func newOTLPRequest(batch *jaeger.Batch) *otlpRequest {
+	resource := otlpResource{}
+	if batch.Process != nil {
+		resource.Attributes = append(resource.Attributes, &otlpKeyValue{
+			Key:   "service.name",
+			Value: otlpString(batch.Process.ServiceName),
+		})
+		resource.Attributes = append(resource.Attributes, newOTLPAttributes(batch.Process.Tags)...)
+	}
+
+	spans := make([]*otlpSpan, 0, len(batch.Spans))
+	for _, span := range batch.Spans {
+		spans = append(spans, newOTLPSpan(span))
+	}
+
+	return &otlpRequest{
+		ResourceSpans: []*otlpResourceSpans{{
+			Resource: resource,
+			ScopeSpans: []*otlpScopeSpans{{
+				Scope: otlpScope{Name: modulePath},
+				Spans: spans,
+			}},
+		}},
+	}
+}
+
+// newOTLPSpan converts the span. The span kind and the status are taken from
+// the span.kind, error and status tags.
+func newOTLPSpan(span *jaeger.Span) *otlpSpan {
+	start := uint64(span.StartTime) * 1000
+	converted := &otlpSpan{
+		TraceID:           newOTLPTraceID(span.TraceIdHigh, span.TraceIdLow),
+		SpanID:            newOTLPSpanID(span.SpanId),
+		Name:              span.OperationName,
+		Kind:              otlpSpanKindInternal,
+		StartTimeUnixNano: start,
+		EndTimeUnixNano:   start + uint64(span.Duration)*1000,
+	}
+	if span.ParentSpanId != 0 {
+		converted.ParentSpanID = newOTLPSpanID(span.ParentSpanId)
+	}
+
+	failed := false
+	for _, tag := range span.Tags {
+		switch tag.Key {
+		case SpanKindTag:
+			converted.Kind = otlpSpanKind(tag.GetVStr())
+			continue
+		case "error":
+			failed = failed || tag.GetVBool() || tag.GetVStr() == "true"
+		case "status":
+			failed = true
+			converted.Status.Message = tag.GetVStr()
+		}
+		converted.Attributes = append(converted.Attributes, newOTLPAttribute(tag))
+	}
+
+	for _, log := range span.Logs {
+		event := &otlpEvent{
+			TimeUnixNano: uint64(log.Timestamp) * 1000,
+			Name:         "log",
+		}
+		for _, field := range log.Fields {
+			switch field.Key {
+			case "event":
+				event.Name = field.GetVStr()
+				continue
+			case "error":
+				if failed {
+					converted.Status.Message = field.GetVStr()
+				}
+			}
+			event.Attributes = append(event.Attributes, newOTLPAttribute(field))
+		}
+		converted.Events = append(converted.Events, event)
+	}
+	if failed {
+		converted.Status.Code = otlpStatusCodeError
+	}
+
+	for _, ref := range span.References {
+		if ref.RefType == jaeger.SpanRefType_CHILD_OF && ref.SpanId == span.ParentSpanId &&
+			ref.TraceIdLow == span.TraceIdLow && ref.TraceIdHigh == span.TraceIdHigh {
+			continue
+		}
+		converted.Links = append(converted.Links, &otlpLink{
+			TraceID: newOTLPTraceID(ref.TraceIdHigh, ref.TraceIdLow),
+			SpanID:  newOTLPSpanID(ref.SpanId),
+		})
+	}
+
+	return converted
+}
+
+func otlpSpanKind(kind string) int32 {
+	switch kind {
+	case SpanKindServer:
+		return otlpSpanKindServer
+	case SpanKindClient:
+		return otlpSpanKindClient
+	case "producer":
+		return otlpSpanKindProducer
+	case "consumer":
+		return otlpSpanKindConsumer
+	default:
+		return otlpSpanKindInternal
+	}
+}
+
+func newOTLPAttributes(tags []*jaeger.Tag) []*otlpKeyValue {
+	attributes := make([]*otlpKeyValue, 0, len(tags))
+	for _, tag := range tags {
+		attributes = append(attributes, newOTLPAttribute(tag))
+	}
+	return attributes
+}
+
+func newOTLPAttribute(tag *jaeger.Tag) *otlpKeyValue {
+	attribute := &otlpKeyValue{Key: tag.Key}
+	switch tag.VType {
+	case jaeger.TagType_BOOL:
+		value := tag.GetVBool()
+		attribute.Value.BoolValue = &value
+	case jaeger.TagType_LONG:
+		value := tag.GetVLong()
+		attribute.Value.IntValue = &value
+	case jaeger.TagType_DOUBLE:
+		value := tag.GetVDouble()
+		attribute.Value.DoubleValue = &value
+	case jaeger.TagType_BINARY:
+		attribute.Value.BytesValue = tag.VBinary
+	default:
+		attribute.Value = otlpString(tag.GetVStr())
+	}
+	return attribute
+}
+
+func otlpString(value string) otlpAnyValue {
+	return otlpAnyValue{StringValue: &value}
+}
+
+// The marshalProto methods encode the messages in the protobuf wire format.
+// Fields with default values are omitted.
+
+func (req *otlpRequest) marshalProto() (b []byte) {
+	for _, resourceSpans := range req.ResourceSpans {
+		b = appendProtoMessage(b, 1, resourceSpans.marshalProto())
+	}
+	return b
+}
+
+func (resourceSpans *otlpResourceSpans) marshalProto() (b []byte) {
+	b = appendProtoMessage(b, 1, resourceSpans.Resource.marshalProto())
+	for _, scopeSpans := range resourceSpans.ScopeSpans {
+		b = appendProtoMessage(b, 2, scopeSpans.marshalProto())
+	}
+	return b
+}
+
+func (resource *otlpResource) marshalProto() (b []byte) {
+	for _, attribute := range resource.Attributes {
+		b = appendProtoMessage(b, 1, attribute.marshalProto())
+	}
+	return b
+}
+
+func (scopeSpans *otlpScopeSpans) marshalProto() (b []byte) {
+	b = appendProtoMessage(b, 1, appendProtoString(nil, 1, scopeSpans.Scope.Name))
+	for _, span := range scopeSpans.Spans {
+		b = appendProtoMessage(b, 2, span.marshalProto())
+	}
+	return b
+}
+
+func (span *otlpSpan) marshalProto() (b []byte) {
+	b = appendProtoMessage(b, 1, span.TraceID)
+	b = appendProtoMessage(b, 2, span.SpanID)
+	if len(span.ParentSpanID) > 0 {
+		b = appendProtoMessage(b, 4, span.ParentSpanID)
+	}
+	b = appendProtoString(b, 5, span.Name)
+	if span.Kind != 0 {
+		b = protowire.AppendTag(b, 6, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(span.Kind))
+	}
+	b = appendProtoFixed64(b, 7, span.StartTimeUnixNano)
+	b = appendProtoFixed64(b, 8, span.EndTimeUnixNano)
+	for _, attribute := range span.Attributes {
+		b = appendProtoMessage(b, 9, attribute.marshalProto())
+	}
+	for _, event := range span.Events {
+		b = appendProtoMessage(b, 11, event.marshalProto())
+	}
+	for _, link := range span.Links {
+		b = appendProtoMessage(b, 13, link.marshalProto())
+	}
+	return appendProtoMessage(b, 15, span.Status.marshalProto())
+}
+
+func (event *otlpEvent) marshalProto() (b []byte) {
+	b = appendProtoFixed64(b, 1, event.TimeUnixNano)
+	b = appendProtoString(b, 2, event.Name)
+	for _, attribute := range event.Attributes {
+		b = appendProtoMessage(b, 3, attribute.marshalProto())
+	}
+	return b
+}
+
+func (link *otlpLink) marshalProto() (b []byte) {
+	b = appendProtoMessage(b, 1, link.TraceID)
+	return appendProtoMessage(b, 2, link.SpanID)
+}
+
+func (status *otlpStatus) marshalProto() (b []byte) {
+	b = appendProtoString(b, 2, status.Message)
+	if status.Code != 0 {
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(status.Code))
+	}
+	return b
+}
+
+func (keyValue *otlpKeyValue) marshalProto() (b []byte) {
+	b = appendProtoString(b, 1, keyValue.Key)
+	return appendProtoMessage(b, 2, keyValue.Value.marshalProto())
+}
+
+func (value *otlpAnyValue) marshalProto() (b []byte) {
+	// the value is a oneof, so its field is set even with a default value.
+	switch {
+	case value.StringValue != nil:
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		return protowire.AppendString(b, *value.StringValue)
+	case value.BoolValue != nil:
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		return protowire.AppendVarint(b, protowire.EncodeBool(*value.BoolValue))
+	case value.IntValue != nil:
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		return protowire.AppendVarint(b, uint64(*value.IntValue))
+	case value.DoubleValue != nil:
+		b = protowire.AppendTag(b, 4, protowire.Fixed64Type)
+		return protowire.AppendFixed64(b, math.Float64bits(*value.DoubleValue))
+	default:
+		b = protowire.AppendTag(b, 7, protowire.BytesType)
+		return protowire.AppendBytes(b, value.BytesValue)
+	}
+}
+
+func appendProtoMessage(b []byte, num protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
+
+func appendProtoString(b []byte, num protowire.Number, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+func appendProtoFixed64(b []byte, num protowire.Number, value uint64) []byte {
+	if value == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, value)
+}
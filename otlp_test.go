@@ -0,0 +1,213 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"storj.io/common/testcontext"
+	"storj.io/monkit-jaeger/gen-go/jaeger"
+)
+
+func newOTLPTestBatch() *jaeger.Batch {
+	parent := int64(0x0102)
+	return &jaeger.Batch{
+		Process: &jaeger.Process{
+			ServiceName: "uplink",
+			Tags:        NewJaegerTags([]Tag{{Key: HostnameTagKey, Value: "host"}}),
+		},
+		Spans: []*jaeger.Span{{
+			TraceIdHigh:   0x0a,
+			TraceIdLow:    0x0b,
+			SpanId:        0x0c,
+			ParentSpanId:  parent,
+			OperationName: "upload",
+			StartTime:     1000,
+			Duration:      500,
+			Tags: NewJaegerTags([]Tag{
+				{Key: SpanKindTag, Value: SpanKindClient},
+				{Key: "bytes", Value: 42},
+				{Key: "ratio", Value: 0.5},
+				{Key: "status", Value: "errored"},
+				NewErrorTag(),
+			}),
+			Logs: newJaegerLogs(time.Unix(0, 1200000), Tag{Key: "error", Value: "disk full"}),
+			References: []*jaeger.SpanRef{
+				{RefType: jaeger.SpanRefType_CHILD_OF, TraceIdHigh: 0x0a, TraceIdLow: 0x0b, SpanId: parent},
+				{RefType: jaeger.SpanRefType_FOLLOWS_FROM, TraceIdLow: 0x0d, SpanId: 0x0e},
+			},
+		}},
+	}
+}
+
+func TestNewOTLPSpan(t *testing.T) {
+	span := newOTLPRequest(newOTLPTestBatch()).ResourceSpans[0].ScopeSpans[0].Spans[0]
+
+	require.Equal(t, "000000000000000a000000000000000b", hexID(span.TraceID))
+	require.Equal(t, "000000000000000c", hexID(span.SpanID))
+	require.Equal(t, "0000000000000102", hexID(span.ParentSpanID))
+	require.Equal(t, int32(otlpSpanKindClient), span.Kind)
+	require.Equal(t, uint64(1000000), span.StartTimeUnixNano)
+	require.Equal(t, uint64(1500000), span.EndTimeUnixNano)
+	require.Equal(t, otlpStatus{Code: otlpStatusCodeError, Message: "disk full"}, span.Status)
+
+	keys := map[string]otlpAnyValue{}
+	for _, attribute := range span.Attributes {
+		keys[attribute.Key] = attribute.Value
+	}
+	require.NotContains(t, keys, SpanKindTag)
+	require.Equal(t, int64(42), *keys["bytes"].IntValue)
+	require.Equal(t, 0.5, *keys["ratio"].DoubleValue)
+	require.True(t, *keys["error"].BoolValue)
+
+	require.Len(t, span.Events, 1)
+	require.Equal(t, uint64(1200000), span.Events[0].TimeUnixNano)
+	require.Equal(t, "error", span.Events[0].Attributes[0].Key)
+
+	require.Len(t, span.Links, 1)
+	require.Equal(t, "000000000000000e", hexID(span.Links[0].SpanID))
+}
+
+func hexID(id otlpID) string {
+	data, _ := id.MarshalJSON()
+	var s string
+	_ = json.Unmarshal(data, &s)
+	return s
+}
+
+func TestOTLPTransport(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	type request struct {
+		path, contentType string
+		body              []byte
+	}
+	requests := make(chan request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests <- request{path: r.URL.Path, contentType: r.Header.Get("Content-Type"), body: body}
+	}))
+	defer server.Close()
+
+	batch := newOTLPTestBatch()
+
+	transport, err := OpenOTLPTransport(ctx, zaptest.NewLogger(t), OTLPSchemePrefix+server.URL, OTLPEncodingJSON, HTTPTransportOptions{})
+	require.NoError(t, err)
+	require.NoError(t, transport.Send(ctx, batch))
+	transport.Close()
+
+	r := <-requests
+	require.Equal(t, OTLPTracesPath, r.path)
+	require.Equal(t, "application/json", r.contentType)
+
+	var decoded struct {
+		ResourceSpans []struct {
+			Resource struct {
+				Attributes []struct {
+					Key   string
+					Value map[string]interface{}
+				}
+			}
+			ScopeSpans []struct {
+				Spans []struct {
+					TraceID           string `json:"traceId"`
+					Kind              int
+					StartTimeUnixNano string
+					Attributes        []struct {
+						Key   string
+						Value map[string]interface{}
+					}
+					Status struct {
+						Code int
+					}
+				}
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(r.body, &decoded))
+	resource := decoded.ResourceSpans[0].Resource
+	require.Equal(t, "service.name", resource.Attributes[0].Key)
+	require.Equal(t, "uplink", resource.Attributes[0].Value["stringValue"])
+	span := decoded.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	require.Equal(t, "000000000000000a000000000000000b", span.TraceID)
+	require.Equal(t, otlpSpanKindClient, span.Kind)
+	require.Equal(t, "1000000", span.StartTimeUnixNano)
+	require.Equal(t, otlpStatusCodeError, span.Status.Code)
+	require.Equal(t, "bytes", span.Attributes[0].Key)
+	require.Equal(t, "42", span.Attributes[0].Value["intValue"])
+
+	transport, err = OpenOTLPTransport(ctx, zaptest.NewLogger(t), server.URL+"/custom/traces", OTLPEncodingProtobuf, HTTPTransportOptions{})
+	require.NoError(t, err)
+	require.NoError(t, transport.Send(ctx, batch))
+	transport.Close()
+
+	r = <-requests
+	require.Equal(t, "/custom/traces", r.path)
+	require.Equal(t, "application/x-protobuf", r.contentType)
+
+	// resource_spans.scope_spans.spans
+	resourceSpans := protoFields(t, protoFields(t, r.body)[1][0].([]byte))
+	resourceAttribute := protoFields(t, protoFields(t, resourceSpans[1][0].([]byte))[1][0].([]byte))
+	require.Equal(t, []byte("service.name"), resourceAttribute[1][0])
+	scopeSpans := protoFields(t, resourceSpans[2][0].([]byte))
+	protoSpan := protoFields(t, scopeSpans[2][0].([]byte))
+	require.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 0x0c}, protoSpan[2][0])
+	require.Equal(t, []byte("upload"), protoSpan[5][0])
+	require.Equal(t, uint64(otlpSpanKindClient), protoSpan[6][0])
+	require.Equal(t, uint64(1000000), protoSpan[7][0])
+	require.Equal(t, uint64(1500000), protoSpan[8][0])
+	require.Len(t, protoSpan[9], 4)
+	require.Len(t, protoSpan[11], 1)
+	require.Len(t, protoSpan[13], 1)
+	status := protoFields(t, protoSpan[15][0].([]byte))
+	require.Equal(t, uint64(otlpStatusCodeError), status[3][0])
+
+	ratio := protoFields(t, protoFields(t, protoSpan[9][1].([]byte))[2][0].([]byte))
+	require.Equal(t, 0.5, math.Float64frombits(ratio[4][0].(uint64)))
+
+	_, err = OpenOTLPTransport(ctx, zaptest.NewLogger(t), server.URL, "xml", HTTPTransportOptions{})
+	require.Error(t, err)
+
+	collector, err := NewThriftCollector(zaptest.NewLogger(t), OTLPSchemePrefix+server.URL, "test", nil, 0, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, otlpTransportType, collector.transportType)
+}
+
+// protoFields decodes the fields of a protobuf message, without decoding
+// nested messages.
+func protoFields(t *testing.T, b []byte) map[protowire.Number][]interface{} {
+	fields := map[protowire.Number][]interface{}{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+
+		var value interface{}
+		switch typ {
+		case protowire.VarintType:
+			value, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			value, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(b)
+		default:
+			t.Fatalf("unexpected wire type %v", typ)
+		}
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		fields[num] = append(fields[num], value)
+	}
+	return fields
+}
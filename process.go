@@ -0,0 +1,256 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"storj.io/common/uuid"
+)
+
+// The process tag keys used by the built-in ProcessTagDetectors. The first
+// four match the ones jaeger clients use.
+const (
+	HostnameTagKey         = "hostname"
+	IPTagKey               = "ip"
+	ClientUUIDTagKey       = "client-uuid"
+	ClientVersionTagKey    = "jaeger.version"
+	GoVersionTagKey        = "go.version"
+	BuildVersionTagKey     = "build.version"
+	BuildRevisionTagKey    = "build.vcs.revision"
+	BuildModifiedTagKey    = "build.vcs.modified"
+	ContainerIDTagKey      = "container.id"
+	ProcessStartTimeTagKey = "process.start_time"
+)
+
+// modulePath is the module path of this package, used to find its version in
+// the build info.
+const modulePath = "storj.io/monkit-jaeger"
+
+// processStartTime is the time the process started. It falls back to the
+// time this package was initialized, if it can't be read from /proc.
+var processStartTime = func() time.Time {
+	stat, _ := os.ReadFile("/proc/self/stat")
+	systemStat, _ := os.ReadFile("/proc/stat")
+	if start, ok := parseProcessStartTime(stat, systemStat); ok {
+		return start
+	}
+	return time.Now()
+}()
+
+// clockTicksPerSecond is the unit of the times in /proc, USER_HZ, which is
+// 100 on all common architectures.
+const clockTicksPerSecond = 100
+
+// parseProcessStartTime returns the start time of the process from its
+// /proc/<pid>/stat, which has it in clock ticks since the boot, and
+// /proc/stat, which has the boot time.
+func parseProcessStartTime(stat, systemStat []byte) (time.Time, bool) {
+	// the command name in parentheses can contain spaces and parentheses.
+	end := bytes.LastIndexByte(stat, ')')
+	if end < 0 {
+		return time.Time{}, false
+	}
+	// the fields after the command name start with the third one, and the
+	// start time is the 22nd.
+	fields := strings.Fields(string(stat[end+1:]))
+	if len(fields) < 20 {
+		return time.Time{}, false
+	}
+	ticks, err := strconv.ParseInt(fields[19], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	for _, line := range strings.Split(string(systemStat), "\n") {
+		if !strings.HasPrefix(line, "btime ") {
+			continue
+		}
+		boot, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(line, "btime ")), 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(boot, 0).Add(time.Duration(ticks) * time.Second / clockTicksPerSecond), true
+	}
+	return time.Time{}, false
+}
+
+// ProcessTagDetector returns tags describing the current process. It returns
+// no tags if it can't detect them.
+type ProcessTagDetector func() []Tag
+
+// DetectProcessTags returns the tags of the detectors followed by tags, for
+// use as the process tags of NewThriftCollector. tags override detected tags
+// with the same key. If no detectors are given, DefaultProcessTagDetectors
+// are used.
+func DetectProcessTags(tags []Tag, detectors ...ProcessTagDetector) []Tag {
+	if len(detectors) == 0 {
+		detectors = DefaultProcessTagDetectors()
+	}
+
+	overridden := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		overridden[tag.Key] = true
+	}
+
+	var detected []Tag
+	for _, detector := range detectors {
+		for _, tag := range detector() {
+			if !overridden[tag.Key] {
+				overridden[tag.Key] = true
+				detected = append(detected, tag)
+			}
+		}
+	}
+	return append(detected, tags...)
+}
+
+// DefaultProcessTagDetectors returns all built-in detectors.
+func DefaultProcessTagDetectors() []ProcessTagDetector {
+	return []ProcessTagDetector{
+		DetectHostname,
+		DetectIP,
+		DetectClientUUID,
+		DetectClientVersion,
+		DetectGoVersion,
+		DetectBuildInfo,
+		DetectContainerID,
+		DetectProcessStartTime,
+	}
+}
+
+// DetectHostname detects the hostname.
+func DetectHostname() []Tag {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return nil
+	}
+	return []Tag{{Key: HostnameTagKey, Value: hostname}}
+}
+
+// DetectIP detects the first non-loopback IP address, preferring IPv4.
+func DetectIP() []Tag {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+
+	var fallback net.IP
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok || ipnet.IP.IsLoopback() || ipnet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if ipnet.IP.To4() != nil {
+			return []Tag{{Key: IPTagKey, Value: ipnet.IP.String()}}
+		}
+		if fallback == nil {
+			fallback = ipnet.IP
+		}
+	}
+	if fallback == nil {
+		return nil
+	}
+	return []Tag{{Key: IPTagKey, Value: fallback.String()}}
+}
+
+// clientUUID identifies this process, so that restarts of a service on the
+// same host can be told apart.
+var clientUUID, clientUUIDErr = uuid.New()
+
+// DetectClientUUID returns a random id of this process.
+func DetectClientUUID() []Tag {
+	if clientUUIDErr != nil {
+		return nil
+	}
+	return []Tag{{Key: ClientUUIDTagKey, Value: clientUUID.String()}}
+}
+
+// DetectClientVersion detects the version of this package.
+func DetectClientVersion() []Tag {
+	version := "unknown"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if info.Main.Path == modulePath {
+			version = info.Main.Version
+		}
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				version = dep.Version
+			}
+		}
+	}
+	return []Tag{{Key: ClientVersionTagKey, Value: "Go-monkit-jaeger-" + version}}
+}
+
+// DetectGoVersion detects the Go version the binary was built with.
+func DetectGoVersion() []Tag {
+	return []Tag{{Key: GoVersionTagKey, Value: runtime.Version()}}
+}
+
+// DetectBuildInfo detects the version and the VCS revision of the main
+// module of the binary.
+func DetectBuildInfo() []Tag {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+
+	var tags []Tag
+	if info.Main.Version != "" {
+		tags = append(tags, Tag{Key: BuildVersionTagKey, Value: info.Main.Version})
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			tags = append(tags, Tag{Key: BuildRevisionTagKey, Value: setting.Value})
+		case "vcs.modified":
+			tags = append(tags, Tag{Key: BuildModifiedTagKey, Value: setting.Value == "true"})
+		}
+	}
+	return tags
+}
+
+var (
+	// cgroupContainerIDPattern matches the container ids of docker,
+	// containerd and cri-o in cgroup paths.
+	cgroupContainerIDPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+	// mountContainerIDPattern matches the container id in the paths of the
+	// files container runtimes mount into containers, like /etc/hostname. Other
+	// paths with ids may belong to other containers.
+	mountContainerIDPattern = regexp.MustCompile(`/containers/([0-9a-f]{64})/`)
+)
+
+// DetectContainerID detects the id of the container the process runs in,
+// based on its cgroups or mounts.
+func DetectContainerID() []Tag {
+	cgroup, _ := os.ReadFile("/proc/self/cgroup")
+	mountinfo, _ := os.ReadFile("/proc/self/mountinfo")
+	if id := parseContainerID(cgroup, mountinfo); id != "" {
+		return []Tag{{Key: ContainerIDTagKey, Value: id}}
+	}
+	return nil
+}
+
+func parseContainerID(cgroup, mountinfo []byte) string {
+	if id := cgroupContainerIDPattern.Find(cgroup); id != nil {
+		return string(id)
+	}
+	if match := mountContainerIDPattern.FindSubmatch(mountinfo); match != nil {
+		return string(match[1])
+	}
+	return ""
+}
+
+// DetectProcessStartTime returns the time the process started.
+func DetectProcessStartTime() []Tag {
+	return []Tag{{Key: ProcessStartTimeTagKey, Value: processStartTime}}
+}
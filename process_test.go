@@ -0,0 +1,63 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectProcessTags(t *testing.T) {
+	detected := func() []Tag {
+		return []Tag{{Key: "hostname", Value: "detected"}, {Key: "ip", Value: "10.0.0.1"}}
+	}
+	tags := DetectProcessTags([]Tag{{Key: "hostname", Value: "override"}}, detected, DetectGoVersion)
+	require.Equal(t, []Tag{
+		{Key: "ip", Value: "10.0.0.1"},
+		{Key: GoVersionTagKey, Value: runtime.Version()},
+		{Key: "hostname", Value: "override"},
+	}, tags)
+
+	keys := map[string]bool{}
+	for _, tag := range DetectProcessTags(nil) {
+		require.False(t, keys[tag.Key], tag.Key)
+		keys[tag.Key] = true
+
+		_, err := tag.BuildJaegerThrift()
+		require.NoError(t, err)
+	}
+	for _, key := range []string{ClientUUIDTagKey, ClientVersionTagKey, GoVersionTagKey, ProcessStartTimeTagKey} {
+		require.True(t, keys[key], key)
+	}
+}
+
+func TestParseContainerID(t *testing.T) {
+	id := strings.Repeat("0123456789abcdef", 4)
+
+	require.Equal(t, id, parseContainerID([]byte("0::/system.slice/docker-"+id+".scope\n"), nil))
+	require.Equal(t, id, parseContainerID(
+		[]byte("0::/\n"),
+		[]byte("1 2 0:1 /var/lib/docker/containers/"+id+"/hostname /etc/hostname rw\n")))
+	require.Equal(t, "", parseContainerID(
+		[]byte("0::/user.slice\n"),
+		[]byte("1 2 0:1 / /var/lib/docker/overlay2/"+id+"/merged rw\n")))
+}
+
+func TestParseProcessStartTime(t *testing.T) {
+	stat := []byte("1234 (my (odd) cmd) S 1 1234 1234 0 -1 4194560 100 0 0 0 5 3 0 0 20 0 8 0 250 1000000 300 18446744073709551615\n")
+	systemStat := []byte("cpu  1 2 3 4\nbtime 1700000000\nprocesses 42\n")
+
+	start, ok := parseProcessStartTime(stat, systemStat)
+	require.True(t, ok)
+	require.Equal(t, time.Unix(1700000002, 500000000), start)
+
+	_, ok = parseProcessStartTime(stat, nil)
+	require.False(t, ok)
+	_, ok = parseProcessStartTime([]byte("1234 (cmd) S 1"), systemStat)
+	require.False(t, ok)
+}
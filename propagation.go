@@ -0,0 +1,358 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+const (
+	// UberTraceIDHeader is the header jaeger clients use to propagate traces.
+	UberTraceIDHeader = "uber-trace-id"
+	// UberBaggageHeaderPrefix is the prefix of the headers jaeger clients use
+	// to propagate baggage.
+	UberBaggageHeaderPrefix = "uberctx-"
+
+	// B3TraceIDHeader is the B3 multi-header trace id.
+	B3TraceIDHeader = "x-b3-traceid"
+	// B3SpanIDHeader is the B3 multi-header span id.
+	B3SpanIDHeader = "x-b3-spanid"
+	// B3ParentSpanIDHeader is the B3 multi-header parent span id.
+	B3ParentSpanIDHeader = "x-b3-parentspanid"
+	// B3SampledHeader is the B3 multi-header sampling decision.
+	B3SampledHeader = "x-b3-sampled"
+	// B3FlagsHeader is the B3 multi-header debug flag.
+	B3FlagsHeader = "x-b3-flags"
+	// B3SingleHeader is the B3 single-header.
+	B3SingleHeader = "b3"
+
+	uberFlagSampled = 0x01
+	uberFlagDebug   = 0x02
+)
+
+// Propagator reads and writes trace information from and to a Carrier.
+type Propagator interface {
+	// Inject writes the trace information of the span in ctx into carrier. It
+	// returns false if ctx has no span.
+	Inject(ctx context.Context, carrier Carrier) bool
+	// Extract returns a new trace and its parent span id based on the trace
+	// information in carrier. It returns a nil trace if carrier has no valid
+	// trace information.
+	Extract(carrier Carrier) (trace *monkit.Trace, parentID int64)
+}
+
+// CompositePropagator injects all formats and extracts the first one found,
+// in order.
+type CompositePropagator []Propagator
+
+var _ Propagator = CompositePropagator{}
+
+// Inject implements Propagator.
+func (c CompositePropagator) Inject(ctx context.Context, carrier Carrier) bool {
+	injected := false
+	for _, p := range c {
+		if p.Inject(ctx, carrier) {
+			injected = true
+		}
+	}
+	return injected
+}
+
+// Extract implements Propagator.
+func (c CompositePropagator) Extract(carrier Carrier) (trace *monkit.Trace, parentID int64) {
+	for _, p := range c {
+		if trace, parentID := p.Extract(carrier); trace != nil {
+			return trace, parentID
+		}
+	}
+	return nil, 0
+}
+
+// TraceContextPropagator propagates traces with the W3C traceparent and
+// tracestate headers.
+type TraceContextPropagator struct{}
+
+var _ Propagator = TraceContextPropagator{}
+
+// Inject implements Propagator.
+func (TraceContextPropagator) Inject(ctx context.Context, carrier Carrier) bool {
+	return InjectTraceContext(ctx, carrier)
+}
+
+// Extract implements Propagator.
+func (TraceContextPropagator) Extract(carrier Carrier) (trace *monkit.Trace, parentID int64) {
+	return ExtractTraceContext(carrier)
+}
+
+// UberPropagator propagates traces with the uber-trace-id header and
+// uberctx- baggage headers used by jaeger clients.
+type UberPropagator struct{}
+
+var _ Propagator = UberPropagator{}
+
+// Inject implements Propagator.
+func (UberPropagator) Inject(ctx context.Context, carrier Carrier) bool {
+	span := monkit.SpanFromCtx(ctx)
+	if span == nil {
+		return false
+	}
+	trace := span.Trace()
+
+	var flags int
+	if sampled, _ := trace.Get(Sampled).(bool); sampled {
+		flags |= uberFlagSampled
+	}
+	if isDebug(trace) {
+		flags |= uberFlagDebug
+	}
+
+	// the parent span id is deprecated and always zero.
+	carrier.Set(UberTraceIDHeader, fmt.Sprintf("%s:%x:0:%x",
+		formatHexTraceID(traceIDHigh(trace), trace.Id()), uint64(span.Id()), flags))
+
+	for key, value := range Baggage(trace) {
+		carrier.Set(UberBaggageHeaderPrefix+key, url.QueryEscape(value))
+	}
+	return true
+}
+
+// Extract implements Propagator.
+func (UberPropagator) Extract(carrier Carrier) (trace *monkit.Trace, parentID int64) {
+	value, err := url.QueryUnescape(carrier.Get(UberTraceIDHeader))
+	if err != nil {
+		return nil, 0
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) != 4 {
+		return nil, 0
+	}
+
+	high, low, ok := parseHexTraceID(parts[0])
+	if !ok {
+		return nil, 0
+	}
+	spanID, ok := parseHexID(parts[1])
+	if !ok {
+		return nil, 0
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return nil, 0
+	}
+
+	trace = newRemoteTrace(high, low)
+	trace.Set(Sampled, flags&uberFlagSampled != 0)
+	if flags&uberFlagDebug != 0 {
+		setDebug(trace)
+	}
+
+	for _, key := range carrier.Keys() {
+		if !strings.HasPrefix(strings.ToLower(key), UberBaggageHeaderPrefix) {
+			continue
+		}
+		value, err := url.QueryUnescape(carrier.Get(key))
+		if err != nil {
+			continue
+		}
+		SetBaggageItem(trace, strings.ToLower(key[len(UberBaggageHeaderPrefix):]), value)
+	}
+
+	return trace, spanID
+}
+
+// B3Propagator propagates traces with the Zipkin B3 multi-headers.
+type B3Propagator struct{}
+
+var _ Propagator = B3Propagator{}
+
+// Inject implements Propagator.
+func (B3Propagator) Inject(ctx context.Context, carrier Carrier) bool {
+	span := monkit.SpanFromCtx(ctx)
+	if span == nil {
+		return false
+	}
+	trace := span.Trace()
+
+	carrier.Set(B3TraceIDHeader, formatB3TraceID(traceIDHigh(trace), trace.Id()))
+	carrier.Set(B3SpanIDHeader, fmt.Sprintf("%016x", uint64(span.Id())))
+	if parentID, ok := span.ParentId(); ok {
+		carrier.Set(B3ParentSpanIDHeader, fmt.Sprintf("%016x", uint64(parentID)))
+	}
+	if isDebug(trace) {
+		carrier.Set(B3FlagsHeader, "1")
+	} else if sampled, ok := trace.Get(Sampled).(bool); ok {
+		carrier.Set(B3SampledHeader, formatB3Sampled(sampled))
+	}
+	return true
+}
+
+// Extract implements Propagator.
+func (B3Propagator) Extract(carrier Carrier) (trace *monkit.Trace, parentID int64) {
+	high, low, ok := parseHexTraceID(carrier.Get(B3TraceIDHeader))
+	if !ok {
+		return nil, 0
+	}
+	spanID, ok := parseHexID(carrier.Get(B3SpanIDHeader))
+	if !ok {
+		return nil, 0
+	}
+
+	trace = newRemoteTrace(high, low)
+	if carrier.Get(B3FlagsHeader) == "1" {
+		// debug implies sampled.
+		setDebug(trace)
+	} else if sampled, ok := parseB3Sampled(carrier.Get(B3SampledHeader)); ok {
+		trace.Set(Sampled, sampled)
+	}
+
+	return trace, spanID
+}
+
+// B3SingleHeaderPropagator propagates traces with the Zipkin B3 single
+// header.
+type B3SingleHeaderPropagator struct{}
+
+var _ Propagator = B3SingleHeaderPropagator{}
+
+// Inject implements Propagator.
+func (B3SingleHeaderPropagator) Inject(ctx context.Context, carrier Carrier) bool {
+	span := monkit.SpanFromCtx(ctx)
+	if span == nil {
+		return false
+	}
+	trace := span.Trace()
+
+	value := formatB3TraceID(traceIDHigh(trace), trace.Id()) + "-" + fmt.Sprintf("%016x", uint64(span.Id()))
+	if sampled, ok := trace.Get(Sampled).(bool); ok {
+		if isDebug(trace) {
+			value += "-d"
+		} else {
+			value += "-" + formatB3Sampled(sampled)
+		}
+		if parentID, ok := span.ParentId(); ok {
+			value += "-" + fmt.Sprintf("%016x", uint64(parentID))
+		}
+	}
+	carrier.Set(B3SingleHeader, value)
+	return true
+}
+
+// Extract implements Propagator.
+func (B3SingleHeaderPropagator) Extract(carrier Carrier) (trace *monkit.Trace, parentID int64) {
+	// the header can be only the sampling decision, in which case there is
+	// no trace to continue.
+	parts := strings.Split(carrier.Get(B3SingleHeader), "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		return nil, 0
+	}
+
+	high, low, ok := parseHexTraceID(parts[0])
+	if !ok {
+		return nil, 0
+	}
+	spanID, ok := parseHexID(parts[1])
+	if !ok {
+		return nil, 0
+	}
+
+	trace = newRemoteTrace(high, low)
+	if len(parts) > 2 {
+		switch parts[2] {
+		case "d":
+			setDebug(trace)
+		default:
+			sampled, ok := parseB3Sampled(parts[2])
+			if !ok {
+				return nil, 0
+			}
+			trace.Set(Sampled, sampled)
+		}
+	}
+
+	return trace, spanID
+}
+
+// newRemoteTrace returns a trace continuing a remote trace. The trace is
+// marked as remote so that no new high trace id bits are generated for it.
+func newRemoteTrace(high, low int64) *monkit.Trace {
+	trace := monkit.NewTrace(low)
+	trace.Set(remoteTraceKey{}, struct{}{})
+	if high != 0 {
+		trace.Set(TraceIDHigh, high)
+	}
+	return trace
+}
+
+// parseHexTraceID parses a 64-bit or 128-bit hex trace id.
+func parseHexTraceID(s string) (high, low int64, ok bool) {
+	if len(s) == 0 || len(s) > 32 {
+		return 0, 0, false
+	}
+
+	var highBits uint64
+	if len(s) > 16 {
+		var err error
+		highBits, err = strconv.ParseUint(s[:len(s)-16], 16, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		s = s[len(s)-16:]
+	}
+	lowBits, err := strconv.ParseUint(s, 16, 64)
+	if err != nil || highBits == 0 && lowBits == 0 {
+		return 0, 0, false
+	}
+	return int64(highBits), int64(lowBits), true
+}
+
+// parseHexID parses a 64-bit hex span id.
+func parseHexID(s string) (int64, bool) {
+	if len(s) == 0 || len(s) > 16 {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(s, 16, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return int64(id), true
+}
+
+func formatHexTraceID(high, low int64) string {
+	if high == 0 {
+		return fmt.Sprintf("%x", uint64(low))
+	}
+	return fmt.Sprintf("%x%016x", uint64(high), uint64(low))
+}
+
+func formatB3TraceID(high, low int64) string {
+	if high == 0 {
+		return fmt.Sprintf("%016x", uint64(low))
+	}
+	return fmt.Sprintf("%016x%016x", uint64(high), uint64(low))
+}
+
+func formatB3Sampled(sampled bool) string {
+	if sampled {
+		return "1"
+	}
+	return "0"
+}
+
+func parseB3Sampled(s string) (sampled, ok bool) {
+	switch s {
+	case "1", "true":
+		return true, true
+	case "0", "false":
+		return false, true
+	default:
+		return false, false
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/stretchr/testify/require"
+)
+
+// withRemoteSpan calls f with a context of a span, which continues trace.
+func withRemoteSpan(trace *monkit.Trace, parentID int64, f func(ctx context.Context, span *monkit.Span)) {
+	ctx := context.Background()
+	defer monkit.NewRegistry().Package().Func().RemoteTrace(&ctx, parentID, trace)(nil)
+	f(ctx, monkit.SpanFromCtx(ctx))
+}
+
+func TestPropagatorsRoundTrip(t *testing.T) {
+	propagators := map[string]Propagator{
+		"trace context": TraceContextPropagator{},
+		"uber":          UberPropagator{},
+		"b3":            B3Propagator{},
+		"b3 single":     B3SingleHeaderPropagator{},
+	}
+
+	for name, propagator := range propagators {
+		propagator := propagator
+		t.Run(name, func(t *testing.T) {
+			for _, high := range []int64{0, 0x1112131415161718} {
+				trace := monkit.NewTrace(monkit.NewId())
+				trace.Set(Sampled, true)
+				if high != 0 {
+					trace.Set(TraceIDHigh, high)
+				}
+
+				withRemoteSpan(trace, 1, func(ctx context.Context, span *monkit.Span) {
+					header := http.Header{}
+					require.True(t, propagator.Inject(ctx, HeaderCarrier(header)))
+
+					extracted, parentID := propagator.Extract(HeaderCarrier(header))
+					require.NotNil(t, extracted)
+					require.Equal(t, trace.Id(), extracted.Id())
+					require.Equal(t, high, traceIDHigh(extracted))
+					require.Equal(t, span.Id(), parentID)
+					require.Equal(t, true, extracted.Get(Sampled))
+				})
+			}
+
+			require.False(t, propagator.Inject(context.Background(), MapCarrier{}))
+			trace, _ := propagator.Extract(MapCarrier{})
+			require.Nil(t, trace)
+		})
+	}
+}
+
+func TestUberPropagator(t *testing.T) {
+	trace, parentID := UberPropagator{}.Extract(MapCarrier{
+		UberTraceIDHeader:                  "4bf92f3577b34da6a3ce929d0e0e4736:00f067aa0ba902b7:0:3",
+		UberBaggageHeaderPrefix + "tenant": "project%201",
+	})
+	require.NotNil(t, trace)
+	require.Equal(t, int64(0x4bf92f3577b34da6), traceIDHigh(trace))
+	require.Equal(t, int64(0x00f067aa0ba902b7), parentID)
+	require.Equal(t, true, trace.Get(Sampled))
+	require.Equal(t, "project 1", BaggageItem(trace, "tenant"))
+
+	// baggage is injected with the trace.
+	withRemoteSpan(trace, parentID, func(ctx context.Context, span *monkit.Span) {
+		header := http.Header{}
+		require.True(t, UberPropagator{}.Inject(ctx, HeaderCarrier(header)))
+		require.Equal(t, "project+1", header.Get("Uberctx-Tenant"))
+
+		extracted, _ := UberPropagator{}.Extract(HeaderCarrier(header))
+		require.Equal(t, "project 1", BaggageItem(extracted, "tenant"))
+	})
+
+	for _, invalid := range []string{
+		"",
+		"abc:def:0",
+		"0:00f067aa0ba902b7:0:1",
+		"4bf92f3577b34da6:0:0:1",
+		"4bf92f3577b34da6:00f067aa0ba902b7:0:xyz",
+		"4bf92f3577b34da6a3ce929d0e0e47361:00f067aa0ba902b7:0:1",
+	} {
+		trace, _ := UberPropagator{}.Extract(MapCarrier{UberTraceIDHeader: invalid})
+		require.Nil(t, trace, invalid)
+	}
+}
+
+func TestB3Propagator(t *testing.T) {
+	trace, parentID := B3Propagator{}.Extract(MapCarrier{
+		B3TraceIDHeader: "a3ce929d0e0e4736",
+		B3SpanIDHeader:  "00f067aa0ba902b7",
+	})
+	require.NotNil(t, trace)
+	require.Equal(t, int64(0x00f067aa0ba902b7), parentID)
+	// without a sampling decision, the trace is sampled locally.
+	require.Nil(t, trace.Get(Sampled))
+
+	trace, _ = B3Propagator{}.Extract(MapCarrier{
+		B3TraceIDHeader: "a3ce929d0e0e4736",
+		B3SpanIDHeader:  "00f067aa0ba902b7",
+		B3SampledHeader: "0",
+	})
+	require.Equal(t, false, trace.Get(Sampled))
+
+	trace, _ = B3Propagator{}.Extract(MapCarrier{
+		B3TraceIDHeader: "a3ce929d0e0e4736",
+		B3SpanIDHeader:  "00f067aa0ba902b7",
+		B3FlagsHeader:   "1",
+	})
+	require.Equal(t, true, trace.Get(Sampled))
+}
+
+func TestB3SingleHeaderPropagator(t *testing.T) {
+	trace, parentID := B3SingleHeaderPropagator{}.Extract(MapCarrier{
+		B3SingleHeader: "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-d-05e3ac9a4f6e3b90",
+	})
+	require.NotNil(t, trace)
+	require.Equal(t, int64(-0x7f0e6711a9cbc458), traceIDHigh(trace)) // 0x80f198ee56343ba8
+	require.Equal(t, int64(0x64fe8b2a57d3eff7), trace.Id())
+	require.Equal(t, int64(-0x1ba84a5d1b27942f), parentID) // 0xe457b5a2e4d86bd1
+	require.Equal(t, true, trace.Get(Sampled))
+
+	for _, invalid := range []string{"0", "1", "d", "a3ce929d0e0e4736-00f067aa0ba902b7-x"} {
+		trace, _ := B3SingleHeaderPropagator{}.Extract(MapCarrier{B3SingleHeader: invalid})
+		require.Nil(t, trace, invalid)
+	}
+}
+
+func TestCompositePropagator(t *testing.T) {
+	propagator := CompositePropagator{TraceContextPropagator{}, B3Propagator{}}
+
+	trace, parentID := propagator.Extract(MapCarrier{
+		B3TraceIDHeader: "a3ce929d0e0e4736",
+		B3SpanIDHeader:  "00f067aa0ba902b7",
+	})
+	require.NotNil(t, trace)
+	require.Equal(t, int64(0x00f067aa0ba902b7), parentID)
+
+	// the first format wins.
+	trace, parentID = RemoteTraceHandler(map[string]string{
+		TraceParentHeader: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000001-01",
+		B3TraceIDHeader:   "a3ce929d0e0e4736",
+		B3SpanIDHeader:    "00f067aa0ba902b7",
+	}, propagator...)
+	require.NotNil(t, trace)
+	require.Equal(t, int64(1), parentID)
+
+	withRemoteSpan(trace, parentID, func(ctx context.Context, span *monkit.Span) {
+		carrier := MapCarrier{}
+		require.True(t, propagator.Inject(ctx, carrier))
+		require.Contains(t, carrier, TraceParentHeader)
+		require.Contains(t, carrier, B3TraceIDHeader)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -30,8 +31,47 @@ func main() {
 	}
 }
 
+// traceKey identifies a trace by its full 128-bit trace id. The high bits are
+// zero for 64-bit trace ids.
+type traceKey struct {
+	high, low int64
+}
+
+func spanTraceKey(span *jaeger.Span) traceKey {
+	return traceKey{high: span.TraceIdHigh, low: span.TraceIdLow}
+}
+
+func (k traceKey) String() string {
+	if k.high == 0 {
+		return strconv.FormatInt(k.low, 10)
+	}
+	return fmt.Sprintf("%016x%016x", uint64(k.high), uint64(k.low))
+}
+
+// parseTraceKey parses a 128-bit trace id given as 32 hex digits, or a 64-bit
+// trace id in any base accepted by strconv.ParseInt.
+func parseTraceKey(s string) (traceKey, error) {
+	if hex := strings.TrimPrefix(s, "0x"); len(hex) == 32 {
+		high, err := strconv.ParseUint(hex[:16], 16, 64)
+		if err != nil {
+			return traceKey{}, errs.Wrap(err)
+		}
+		low, err := strconv.ParseUint(hex[16:], 16, 64)
+		if err != nil {
+			return traceKey{}, errs.Wrap(err)
+		}
+		return traceKey{high: int64(high), low: int64(low)}, nil
+	}
+
+	low, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		return traceKey{}, errs.Wrap(err)
+	}
+	return traceKey{low: low}, nil
+}
+
 type subscription struct {
-	traceID int64
+	traceID traceKey
 	ch      chan *jaeger.Span
 }
 
@@ -39,8 +79,8 @@ type server struct {
 	mu     sync.Mutex
 	active map[*http.Request]*subscription
 
-	rbufs map[int64][]*jaeger.Span
-	rbuft []int64
+	rbufs map[traceKey][]*jaeger.Span
+	rbuft []traceKey
 	rbufn int
 }
 
@@ -56,7 +96,7 @@ func run(ctx context.Context, iface, laddr string) (err error) {
 
 	s := &server{
 		active: make(map[*http.Request]*subscription),
-		rbufs:  make(map[int64][]*jaeger.Span),
+		rbufs:  make(map[traceKey][]*jaeger.Span),
 	}
 
 	errch := make(chan error, 2)
@@ -107,11 +147,12 @@ func (s *server) handlePacket(ctx context.Context, buf []byte) (err error) {
 	defer s.mu.Unlock()
 
 	for _, span := range batch.Batch.GetSpans() {
-		rbuf, ok := s.rbufs[span.TraceIdLow]
+		key := spanTraceKey(span)
+		rbuf, ok := s.rbufs[key]
 		if !ok {
-			s.rbuft = append(s.rbuft, span.TraceIdLow)
+			s.rbuft = append(s.rbuft, key)
 		}
-		s.rbufs[span.TraceIdLow] = append(rbuf, span)
+		s.rbufs[key] = append(rbuf, span)
 		s.rbufn++
 
 		if s.rbufn >= 1024*1024 {
@@ -123,11 +164,11 @@ func (s *server) handlePacket(ctx context.Context, buf []byte) (err error) {
 
 		// this _should_ be relatively small compared to the other buffer
 		for _, sub := range s.active {
-			if sub.traceID == span.TraceIdLow {
+			if sub.traceID == key {
 				select {
 				case sub.ch <- span:
 				default:
-					log.Printf("dropped trace for %d", span.TraceIdLow)
+					log.Printf("dropped trace for %s", key)
 				}
 			}
 		}
@@ -160,14 +201,14 @@ func (s *server) Subscribe(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// which trace we're looking at
-	traceID, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/"), 0, 64)
+	traceID, err := parseTraceKey(strings.TrimPrefix(r.URL.Path, "/"))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	log.Printf("filtering traces for %d (wait=%s)", traceID, strconv.FormatBool(wait))
-	defer log.Printf("done filtering traces for %d (wait=%s)", traceID, strconv.FormatBool(wait))
+	log.Printf("filtering traces for %s (wait=%s)", traceID, strconv.FormatBool(wait))
+	defer log.Printf("done filtering traces for %s (wait=%s)", traceID, strconv.FormatBool(wait))
 
 	// stream json spans back to the client
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,151 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+// RedactAction is what a RedactionRule does with a matching tag.
+type RedactAction int
+
+const (
+	// RedactDrop removes the tag.
+	RedactDrop RedactAction = iota
+	// RedactHash replaces the value with a keyed hash of it. The key is
+	// random per process, so hashes can only be correlated within a process.
+	RedactHash
+	// RedactTruncate shortens the value to RedactionRule.MaxLength bytes.
+	RedactTruncate
+	// RedactMask replaces the value with RedactionRule.Mask.
+	RedactMask
+)
+
+// String implements fmt.Stringer.
+func (action RedactAction) String() string {
+	switch action {
+	case RedactDrop:
+		return "drop"
+	case RedactHash:
+		return "hash"
+	case RedactTruncate:
+		return "truncate"
+	case RedactMask:
+		return "mask"
+	default:
+		return fmt.Sprintf("RedactAction(%d)", int(action))
+	}
+}
+
+// DefaultRedactionMask is the mask used by RedactMask if none is configured.
+const DefaultRedactionMask = "[redacted]"
+
+// RedactionRule redacts the tags of exported spans. A tag matches the rule if
+// both the key and the value match. Non-string values are matched in their
+// fmt.Sprint form.
+type RedactionRule struct {
+	// Key matches the tag keys. If nil, all keys match.
+	Key *regexp.Regexp
+	// Value matches the tag values. If nil, all values match. RedactHash and
+	// RedactMask only replace the matching parts of the value.
+	Value *regexp.Regexp
+
+	Action RedactAction
+	// MaxLength is the length RedactTruncate shortens values to. RedactTruncate
+	// rules without a positive MaxLength drop the matching tags.
+	MaxLength int
+	// Mask replaces values for RedactMask. Defaults to DefaultRedactionMask.
+	Mask string
+}
+
+// redactionSalt keys the hashes of RedactHash.
+var redactionSalt = func() []byte {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		panic(err)
+	}
+	return salt
+}()
+
+// redactTags applies the rules in order to the tags, and returns the tags
+// that are left.
+func redactTags(rules []RedactionRule, tags []Tag) []Tag {
+	if len(rules) == 0 {
+		return tags
+	}
+
+	redacted := tags[:0]
+next:
+	for _, tag := range tags {
+		for _, rule := range rules {
+			var dropped bool
+			tag, dropped = rule.apply(tag)
+			if dropped {
+				continue next
+			}
+		}
+		redacted = append(redacted, tag)
+	}
+	return redacted
+}
+
+// apply redacts the tag, if it matches the rule. It returns true if the tag
+// has to be dropped.
+func (rule *RedactionRule) apply(tag Tag) (_ Tag, dropped bool) {
+	if rule.Key != nil && !rule.Key.MatchString(tag.Key) {
+		return tag, false
+	}
+
+	value, ok := tag.Value.(string)
+	if !ok {
+		value = fmt.Sprint(tag.Value)
+	}
+	if rule.Value != nil && !rule.Value.MatchString(value) {
+		return tag, false
+	}
+
+	mon.Counter("jaeger_redacted", monkit.NewSeriesTag("action", rule.Action.String())).Inc(1)
+
+	switch rule.Action {
+	case RedactHash:
+		tag.Value = rule.replace(value, hashRedacted)
+	case RedactTruncate:
+		if rule.MaxLength <= 0 {
+			// never export values a misconfigured rule was meant to redact.
+			mon.Counter("jaeger_redaction_invalid").Inc(1)
+			return tag, true
+		}
+		tag.Value = truncateUTF8(value, rule.MaxLength)
+	case RedactMask:
+		mask := rule.Mask
+		if mask == "" {
+			mask = DefaultRedactionMask
+		}
+		tag.Value = rule.replace(value, func(string) string { return mask })
+	default:
+		return tag, true
+	}
+	return tag, false
+}
+
+// replace replaces the parts of value matching the rule.
+func (rule *RedactionRule) replace(value string, replacement func(string) string) string {
+	if rule.Value == nil {
+		return replacement(value)
+	}
+	return rule.Value.ReplaceAllStringFunc(value, replacement)
+}
+
+func hashRedacted(value string) string {
+	mac := hmac.New(sha256.New, redactionSalt)
+	_, _ = mac.Write([]byte(value))
+	return "hash:" + hex.EncodeToString(mac.Sum(nil)[:8])
+}
@@ -0,0 +1,86 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactTags(t *testing.T) {
+	rules := []RedactionRule{
+		{Key: regexp.MustCompile(`^secret$`), Action: RedactDrop},
+		{Key: regexp.MustCompile(`^bucket$`), Action: RedactHash},
+		{Key: regexp.MustCompile(`^object$`), Action: RedactTruncate, MaxLength: 3},
+		{Value: regexp.MustCompile(`\d+\.\d+\.\d+\.\d+`), Action: RedactMask},
+		{Key: regexp.MustCompile(`^count$`), Value: regexp.MustCompile(`^4`), Action: RedactMask, Mask: "x"},
+	}
+
+	tags := redactTags(rules, []Tag{
+		{Key: "secret", Value: "password"},
+		{Key: "bucket", Value: "photos"},
+		{Key: "other-bucket", Value: "photos"},
+		{Key: "object", Value: "holiday.jpg"},
+		{Key: "peer", Value: "dialing 10.0.0.1:7777"},
+		{Key: "count", Value: 42},
+		{Key: "unrelated", Value: 3},
+	})
+
+	require.Equal(t, []Tag{
+		{Key: "bucket", Value: hashRedacted("photos")},
+		{Key: "other-bucket", Value: "photos"},
+		{Key: "object", Value: "hol"},
+		{Key: "peer", Value: "dialing " + DefaultRedactionMask + ":7777"},
+		{Key: "count", Value: "x2"},
+		{Key: "unrelated", Value: 3},
+	}, tags)
+
+	require.Equal(t, hashRedacted("photos"), hashRedacted("photos"))
+	require.NotEqual(t, hashRedacted("photos"), hashRedacted("videos"))
+
+	// truncating rules without a positive length drop the matching tags.
+	for _, maxLength := range []int{0, -1} {
+		tags := redactTags([]RedactionRule{{Key: regexp.MustCompile(`^object$`), Action: RedactTruncate, MaxLength: maxLength}}, []Tag{
+			{Key: "object", Value: "holiday.jpg"},
+			{Key: "bucket", Value: "photos"},
+		})
+		require.Equal(t, []Tag{{Key: "bucket", Value: "photos"}}, tags)
+	}
+}
+
+func TestRegisterJaegerRedactions(t *testing.T) {
+	r := monkit.NewRegistry()
+	collector := &memoryCollector{}
+	unregister := RegisterJaeger(r, collector, Options{
+		Fraction: 1,
+		Redactions: []RedactionRule{
+			{Key: regexp.MustCompile(`^(bucket|tenant)$`), Action: RedactDrop},
+		},
+	})
+	defer unregister()
+
+	func() {
+		ctx := context.Background()
+		defer r.Package().FuncNamed("root").Task(&ctx)(nil)
+		span := monkit.SpanFromCtx(ctx)
+		span.Trace().Set("tenant", "acme")
+		span.Annotate("bucket", "photos")
+		span.Annotate("operation", "upload")
+	}()
+
+	spans := collector.Spans()
+	require.Len(t, spans, 1)
+
+	_, ok := findTag("bucket", spans[0])
+	require.False(t, ok)
+	_, ok = findTag("tenant", spans[0])
+	require.False(t, ok)
+	tag, ok := findTag("operation", spans[0])
+	require.True(t, ok)
+	require.Equal(t, "upload", tag.GetVStr())
+}
@@ -16,7 +16,6 @@ import (
 	"github.com/spacemonkeygo/monkit/v3"
 	"github.com/spacemonkeygo/monkit/v3/present"
 	"github.com/zeebo/errs"
-	"github.com/zeebo/mwc"
 
 	"storj.io/common/rpc/rpcstatus"
 	"storj.io/monkit-jaeger/gen-go/jaeger"
@@ -41,7 +40,11 @@ type TraceCollectorFactory interface {
 
 // Options represents the configuration for the register.
 type Options struct {
-	Fraction float64 // The Fraction of traces to observe.
+	Fraction float64 // The Fraction of traces to observe. Ignored if Sampler is set.
+
+	// Sampler decides which new traces to observe. If nil, a
+	// ProbabilisticSampler with Fraction is used.
+	Sampler Sampler
 
 	// If set and a trace has a trace host set, this will be called. If the
 	// CollectorFactory fails, the default collector will be used.
@@ -50,11 +53,40 @@ type Options struct {
 	CollectorFactoryHostMatch *regexp.Regexp
 
 	Excluded func(*monkit.Span) bool
+
+	// TailSampling enables tail-based sampling for traces that were not
+	// sampled up front. See TailSamplingOptions.
+	TailSampling *TailSamplingOptions
+
+	// TraceID128Bit generates the high 64 bits of the trace id for new
+	// traces, so that they are exported with 128-bit trace ids. Remote
+	// traces keep the trace id they were propagated with.
+	TraceID128Bit bool
+
+	// ErrorClassifier classifies the errors of failed spans. The class is
+	// exported as the ErrorKindTag tag and log field. If nil,
+	// DefaultErrorClassifier is used.
+	ErrorClassifier ErrorClassifier
+
+	// Redactions are applied in order to the annotations, baggage and trace
+	// metadata of spans before they are exported.
+	Redactions []RedactionRule
+
+	// Baggage restricts the baggage of the traces.
+	Baggage BaggageOptions
+	// BaggageTags exports the baggage of traces as span tags, see
+	// BaggageTagMode.
+	BaggageTags BaggageTagMode
 }
 
 type service struct {
 	Options
 	collector TraceCollector
+	sampler   Sampler
+	tail      *tailSampler
+	baggage   *baggagePolicy
+
+	traceMu sync.Mutex
 
 	collectors     sync.Map
 	collectorCount atomic.Int32
@@ -101,6 +133,18 @@ func (srv *service) getCollector(targetHost string) TraceCollector {
 
 type observedKey struct{}
 
+type samplingKey struct{}
+
+type remoteTraceKey struct{}
+
+// rootSamplingTags are the tags of the sampling decision, which are attached
+// to the span the decision was made for. spanID is zero if the decision was
+// made before the root span started, and the tags go to the local root.
+type rootSamplingTags struct {
+	spanID int64
+	tags   []Tag
+}
+
 // RegisterJaeger configures the given Registry reg to send the Spans from some
 // portion of all new Traces to the given TraceCollector.
 // it returns the unregister function.
@@ -109,37 +153,174 @@ func RegisterJaeger(reg *monkit.Registry, collector TraceCollector,
 	srv := &service{
 		Options:   opts,
 		collector: collector,
+		sampler:   opts.Sampler,
+		baggage:   newBaggagePolicy(opts.Baggage),
+	}
+	if srv.sampler == nil {
+		srv.sampler = NewProbabilisticSampler(opts.Fraction)
+	}
+	if opts.TailSampling != nil {
+		srv.tail = newTailSampler(srv, *opts.TailSampling)
 	}
-
-	var traceMu sync.Mutex
 
 	var cb func(*monkit.Trace)
 	cb = func(t *monkit.Trace) {
 		if _, exists := t.Get(present.SampledCBKey).(func(*monkit.Trace)); !exists {
 			t.Set(present.SampledCBKey, cb)
 		}
+		srv.baggage.attach(t)
 
 		sampled, exists := t.Get(Sampled).(bool)
-		if !exists {
-			sampled = mwc.Rand().Float64() < srv.Fraction
-			t.Set(Sampled, sampled)
+		if !exists && srv.TraceID128Bit {
+			srv.generateTraceIDHigh(t)
+		}
+		if !exists && srv.tail == nil && !needsRoot(srv.sampler) {
+			sampled = srv.decide(t, nil)
+			exists = true
+		}
+		if !exists || (!sampled && srv.tail != nil) {
+			// the sampler or the tail sampler need the root span, which
+			// doesn't exist yet.
+			srv.deferSampling(t)
+			return
 		}
 
 		if !sampled {
 			return
 		}
 
-		traceMu.Lock()
-		defer traceMu.Unlock()
+		if srv.markObserved(t) {
+			// when the trace is new, the first span started is the local root.
+			t.ObserveSpans(&spanObserver{srv: srv, trace: t, findRoot: t.Spans() == 0})
+		}
+	}
+	return reg.ObserveTraces(cb)
+}
 
-		if t.Get(observedKey{}) != nil {
-			return
+// markObserved returns true if the trace wasn't observed before.
+func (srv *service) markObserved(t *monkit.Trace) bool {
+	srv.traceMu.Lock()
+	defer srv.traceMu.Unlock()
+
+	if t.Get(observedKey{}) != nil {
+		return false
+	}
+	t.Set(observedKey{}, struct{}{})
+	return true
+}
+
+// generateTraceIDHigh sets the high trace id bits of new local traces.
+func (srv *service) generateTraceIDHigh(t *monkit.Trace) {
+	if t.Get(remoteTraceKey{}) != nil {
+		return
+	}
+	if _, ok := t.Get(TraceIDHigh).(int64); ok {
+		return
+	}
+	t.Set(TraceIDHigh, monkit.NewId())
+}
+
+// deferSampling registers an observer that asks the sampler once the root
+// span of the trace starts.
+func (srv *service) deferSampling(t *monkit.Trace) {
+	srv.traceMu.Lock()
+	defer srv.traceMu.Unlock()
+
+	if t.Get(samplingKey{}) != nil {
+		return
+	}
+	t.Set(samplingKey{}, struct{}{})
+
+	t.ObserveSpans(&samplingObserver{srv: srv, trace: t})
+}
+
+// decide asks the sampler whether to sample the trace, and records the
+// decision, unless the tail sampler decides later. root is nil if the sampler
+// doesn't need it.
+func (srv *service) decide(t *monkit.Trace, root *monkit.Span) bool {
+	decision := srv.sampler.Sample(t, root)
+	if !decision.Sample && srv.tail != nil {
+		// the tail sampler can still keep the trace, so the decision is left
+		// open, and downstream services defer it too.
+		return false
+	}
+	t.Set(Sampled, decision.Sample)
+	if decision.Sample {
+		sampling := rootSamplingTags{tags: decision.Tags}
+		if root != nil {
+			sampling.spanID = root.Id()
 		}
-		t.Set(observedKey{}, struct{}{})
+		t.Set(samplingKey{}, sampling)
+	}
+	return decision.Sample
+}
+
+// samplingObserver makes the sampling decision when the first span of a
+// trace starts. It stays registered for the rest of the trace, so it also
+// forwards the finished spans of sampled traces, or hands them to the tail
+// sampler.
+type samplingObserver struct {
+	srv   *service
+	trace *monkit.Trace
+
+	once    sync.Once
+	sampled atomic.Bool
+	pending atomic.Pointer[pendingTrace]
+}
+
+func (o *samplingObserver) Start(s *monkit.Span) {
+	o.once.Do(func() { o.decide(s) })
+}
+
+func (o *samplingObserver) decide(root *monkit.Span) {
+	markLocalRoot(o.trace, root)
 
-		t.ObserveSpans(spanFinishObserverFunc(srv.observeSpan))
+	// the decision could have been made remotely or forced in the meantime.
+	sampled, exists := o.trace.Get(Sampled).(bool)
+	if !exists {
+		sampled = o.srv.decide(o.trace, root)
 	}
-	return reg.ObserveTraces(cb)
+
+	if sampled {
+		if o.srv.markObserved(o.trace) {
+			o.sampled.Store(true)
+		}
+		return
+	}
+
+	if o.srv.tail != nil {
+		o.pending.Store(o.srv.tail.track(o.trace, root))
+	}
+}
+
+func (o *samplingObserver) Finish(s *monkit.Span, err error, panicked bool, finish time.Time) {
+	if o.sampled.Load() {
+		o.srv.observeSpan(s, err, panicked, finish)
+		return
+	}
+	if pending := o.pending.Load(); pending != nil {
+		o.srv.tail.finish(pending, s, err, panicked, finish)
+	}
+}
+
+// spanObserver sends the finished spans of a sampled trace to the
+// collector.
+type spanObserver struct {
+	srv      *service
+	trace    *monkit.Trace
+	findRoot bool
+
+	once sync.Once
+}
+
+func (o *spanObserver) Start(s *monkit.Span) {
+	if o.findRoot {
+		o.once.Do(func() { markLocalRoot(o.trace, s) })
+	}
+}
+
+func (o *spanObserver) Finish(s *monkit.Span, err error, panicked bool, finish time.Time) {
+	o.srv.observeSpan(s, err, panicked, finish)
 }
 
 type spanFinishObserverFunc func(s *monkit.Span, err error, panicked bool,
@@ -154,19 +335,32 @@ func (f spanFinishObserverFunc) Finish(s *monkit.Span, err error,
 func (srv *service) observeSpan(s *monkit.Span, spanErr error, panicked bool,
 	finish time.Time) {
 
-	if srv.Excluded != nil && srv.Excluded(s) {
+	js := srv.newJaegerSpan(s, spanErr, panicked, finish)
+	if js == nil {
 		return
 	}
 
+	traceHost, _ := s.Trace().Get(TraceHost).(string)
+	srv.getCollector(traceHost).Collect(js)
+}
+
+// newJaegerSpan converts a finished span into jaeger format. It returns nil
+// if the span is excluded.
+func (srv *service) newJaegerSpan(s *monkit.Span, spanErr error, panicked bool,
+	finish time.Time) *jaeger.Span {
+
+	if srv.Excluded != nil && srv.Excluded(s) {
+		return nil
+	}
+
 	trace := s.Trace()
-	traceHost, _ := trace.Get(TraceHost).(string)
 
 	startTime := s.Start().UnixNano() / 1000
 	duration := finish.Sub(s.Start())
 
 	js := &jaeger.Span{
 		TraceIdLow:    trace.Id(),
-		TraceIdHigh:   0,
+		TraceIdHigh:   traceIDHigh(trace),
 		OperationName: s.Func().FullName(),
 		SpanId:        s.Id(),
 		StartTime:     startTime,
@@ -175,9 +369,20 @@ func (srv *service) observeSpan(s *monkit.Span, spanErr error, panicked bool,
 		Duration: duration.Nanoseconds() / int64(time.Microsecond),
 	}
 
+	js.Flags = flagSampled
+	if isDebug(trace) {
+		js.Flags |= flagDebug
+	}
+
 	pid, hasParent := s.ParentId()
 	if hasParent {
 		js.ParentSpanId = pid
+		js.References = []*jaeger.SpanRef{{
+			RefType:     jaeger.SpanRefType_CHILD_OF,
+			TraceIdLow:  js.TraceIdLow,
+			TraceIdHigh: js.TraceIdHigh,
+			SpanId:      pid,
+		}}
 	}
 
 	tags := make([]Tag, 0, len(s.Annotations()))
@@ -190,6 +395,27 @@ func (srv *service) observeSpan(s *monkit.Span, spanErr error, panicked bool,
 		})
 	}
 
+	if _, ok := spanKind(s); !ok && hasRemoteParent(s) {
+		tags = append(tags, Tag{
+			Key:   SpanKindTag,
+			Value: SpanKindServer,
+		})
+	}
+
+	if sampling, ok := trace.Get(samplingKey{}).(rootSamplingTags); ok &&
+		(sampling.spanID == s.Id() || sampling.spanID == 0 && isLocalRoot(s)) {
+		tags = append(tags, sampling.tags...)
+	}
+
+	switch srv.BaggageTags {
+	case BaggageTagsAllSpans:
+		tags = append(tags, baggageTags(trace)...)
+	case BaggageTagsLocalRoot:
+		if isLocalRoot(s) {
+			tags = append(tags, baggageTags(trace)...)
+		}
+	}
+
 	// only attach trace metadata to the root span
 	if !hasParent {
 		for k, v := range trace.GetAll() {
@@ -201,7 +427,9 @@ func (srv *service) observeSpan(s *monkit.Span, spanErr error, panicked bool,
 			if key == ParentID ||
 				key == Sampled ||
 				key == TraceID ||
-				key == TraceHost {
+				key == TraceIDHigh ||
+				key == TraceHost ||
+				key == TraceState {
 				continue
 			}
 			tags = append(tags, Tag{
@@ -211,18 +439,10 @@ func (srv *service) observeSpan(s *monkit.Span, spanErr error, panicked bool,
 		}
 	}
 
-	if panicked || spanErr != nil {
-		var status string
-
-		switch {
-		case panicked:
-			status = "panicked"
-		case errors.Is(spanErr, context.Canceled):
-			status = "canceled"
-		case spanErr != nil:
-			status = "errored"
-		}
+	// annotations and trace metadata may contain private information.
+	tags = redactTags(srv.Redactions, tags)
 
+	if status := spanStatus(spanErr, panicked); status != "" {
 		tags = append(tags, Tag{
 			Key:   "status",
 			Value: status,
@@ -232,18 +452,53 @@ func (srv *service) observeSpan(s *monkit.Span, spanErr error, panicked bool,
 	// in order to make sure we don't send error messages that contain private
 	// user information to our jaeger instance, we only send errors that we know
 	// is privacy clear.
-	errMsg := filterErr(spanErr, panicked)
-	if errMsg != nil {
-		tags = append(tags, NewErrorTag())
+	// the error class is privacy clear as well, see ErrorClassifier.
+	var fields []Tag
+	if errMsg := filterErr(spanErr, panicked); errMsg != nil {
+		fields = append(fields, Tag{Key: "error", Value: errMsg.Error()})
+	}
+	if class := srv.classifyError(spanErr, panicked); class != "" {
+		tags = append(tags, Tag{Key: ErrorKindTag, Value: class})
+		fields = append(fields, Tag{Key: ErrorKindTag, Value: class})
+	}
+	if len(fields) > 0 {
+		// the span may already be marked as failed with an annotation.
+		if !hasTag(tags, "error") {
+			tags = append(tags, NewErrorTag())
+		}
 
-		js.Logs = newJaegerLogs(finish, "error", errMsg.Error())
+		js.Logs = newJaegerLogs(finish, fields...)
 	}
 	js.Tags = NewJaegerTags(tags)
 
-	srv.getCollector(traceHost).Collect(js)
+	return js
+}
+
+func hasTag(tags []Tag, key string) bool {
+	for _, tag := range tags {
+		if tag.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// spanStatus returns the status tag value of a finished span, or an empty
+// string if the span succeeded.
+func spanStatus(spanErr error, panicked bool) string {
+	switch {
+	case panicked:
+		return "panicked"
+	case errors.Is(spanErr, context.Canceled):
+		return "canceled"
+	case spanErr != nil:
+		return "errored"
+	default:
+		return ""
+	}
 }
 
-func newJaegerLogs(t time.Time, key, msg string) []*jaeger.Log {
+func newJaegerLogs(t time.Time, fields ...Tag) []*jaeger.Log {
 	// converts Go time.Time to a long representing time since epoch in microseconds,
 	// which is used expected in the Jaeger spans encoded as Thrift.
 	timestamp := t.UnixNano() / 1000
@@ -251,12 +506,7 @@ func newJaegerLogs(t time.Time, key, msg string) []*jaeger.Log {
 	return []*jaeger.Log{
 		{
 			Timestamp: timestamp,
-			Fields: NewJaegerTags([]Tag{
-				{
-					Key:   key,
-					Value: msg,
-				},
-			}),
+			Fields:    NewJaegerTags(fields),
 		},
 	}
 }
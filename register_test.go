@@ -5,6 +5,7 @@ package jaeger
 
 import (
 	"context"
+	"strconv"
 	"testing"
 	"time"
 
@@ -46,7 +47,12 @@ func TestRegisterJaeger(t *testing.T) {
 			e: expected{
 				operationName: "test-register-parent",
 				hasParentID:   true,
-				tags:          NewJaegerTags(nil),
+				tags: NewJaegerTags([]Tag{
+					{
+						Key:   SpanKindTag,
+						Value: SpanKindServer,
+					},
+				}),
 			},
 			f: func(r *monkit.Registry, e expected) {
 				newTraceWithParent(ctx, r.Package(), e.operationName)
@@ -102,8 +108,10 @@ func TestRegisterJaeger(t *testing.T) {
 					span := spans[0]
 					require.Contains(t, span.GetOperationName(), test.e.operationName)
 					require.Equal(t, test.e.hasParentID, span.GetParentSpanId() != 0)
-					require.Equal(t, len(test.e.tags), len(span.GetTags()))
-					for _, tag := range test.e.tags {
+					// the root span also records the sampling decision
+					expectedTags := append(NewJaegerTags(samplerTags(samplerTypeProbabilistic, float64(1))), test.e.tags...)
+					require.Equal(t, len(expectedTags), len(span.GetTags()))
+					for _, tag := range expectedTags {
 						actualTag, ok := findTag(tag.GetKey(), span)
 						require.True(t, ok)
 						require.Equal(t, tag.GetVType(), actualTag.GetVType())
@@ -146,3 +154,47 @@ func findTag(key string, s *jaeger.Span) (*jaeger.Tag, bool) {
 	}
 	return nil, false
 }
+
+func TestRegisterJaegerTraceID128Bit(t *testing.T) {
+	ctx := context.Background()
+	r := monkit.NewRegistry()
+	collector := &memoryCollector{}
+
+	unregister := RegisterJaeger(r, collector, Options{
+		Fraction:      1,
+		TraceID128Bit: true,
+	})
+	defer unregister()
+
+	// new traces get the high trace id bits.
+	var remoteInfo map[string]string
+	func() {
+		defer r.Package().FuncNamed("local").Task(&ctx)(nil)
+		remoteInfo = InjectRemoteInfo(ctx)
+	}()
+
+	spans := collector.Spans()
+	require.Len(t, spans, 1)
+	require.NotZero(t, spans[0].TraceIdHigh)
+	require.Equal(t, strconv.FormatInt(spans[0].TraceIdHigh, 10), remoteInfo[TraceIDHigh])
+
+	// remote traces keep their trace id, 128-bit or not.
+	for _, high := range []int64{spans[0].TraceIdHigh, 0} {
+		collector.spans = nil
+		if high == 0 {
+			delete(remoteInfo, TraceIDHigh)
+		}
+
+		trace, parentID := RemoteTraceHandler(remoteInfo)
+		require.NotNil(t, trace)
+		func() {
+			ctx := context.Background()
+			defer r.Package().FuncNamed("remote").RemoteTrace(&ctx, parentID, trace)(nil)
+		}()
+
+		spans := collector.Spans()
+		require.Len(t, spans, 1)
+		require.Equal(t, high, spans[0].TraceIdHigh)
+		require.Equal(t, trace.Id(), spans[0].TraceIdLow)
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultSamplingRefreshInterval is the default interval to fetch the
+	// sampling strategy.
+	defaultSamplingRefreshInterval = time.Minute
+
+	// defaultSamplingRate is the sampling rate used until a strategy is
+	// fetched, if no default sampler is configured.
+	defaultSamplingRate = 0.001
+
+	// maxSamplingResponseSize limits how much of the sampling endpoint
+	// response is read.
+	maxSamplingResponseSize = 1 << 20
+)
+
+// RemoteSamplerOptions configures a RemoteSampler.
+type RemoteSamplerOptions struct {
+	// URL is the address of the sampling endpoint, e.g.
+	// http://localhost:5778/sampling.
+	URL string
+	// ServiceName is sent to the sampling endpoint in the service query
+	// parameter.
+	ServiceName string
+
+	// RefreshInterval is the interval to fetch the strategy. Defaults to one
+	// minute.
+	RefreshInterval time.Duration
+	// MaxStaleness is how long the last fetched strategy is used when the
+	// endpoint is not reachable, before falling back to Default. Defaults to
+	// five refresh intervals.
+	MaxStaleness time.Duration
+
+	// Default is used until a strategy is fetched, and when the endpoint is
+	// unreachable for longer than MaxStaleness. Defaults to a
+	// ProbabilisticSampler with a rate of 0.001.
+	Default Sampler
+	// Client is used to fetch the strategy. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// RemoteSampler is a Sampler that periodically fetches its sampling strategy
+// from a Jaeger compatible sampling endpoint, so the sampling can be changed
+// at runtime.
+type RemoteSampler struct {
+	log    *zap.Logger
+	opts   RemoteSamplerOptions
+	url    string
+	client *http.Client
+
+	mu          sync.Mutex
+	sampler     Sampler
+	strategy    *samplingStrategyResponse
+	lastSuccess time.Time
+}
+
+var _ Sampler = &RemoteSampler{}
+
+// NewRemoteSampler creates a new RemoteSampler. The strategy is fetched once
+// Run or Update is called.
+func NewRemoteSampler(log *zap.Logger, opts RemoteSamplerOptions) (*RemoteSampler, error) {
+	parsedURL, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	query := parsedURL.Query()
+	query.Set("service", opts.ServiceName)
+	parsedURL.RawQuery = query.Encode()
+
+	if opts.RefreshInterval == 0 {
+		opts.RefreshInterval = defaultSamplingRefreshInterval
+	}
+	if opts.MaxStaleness == 0 {
+		opts.MaxStaleness = 5 * opts.RefreshInterval
+	}
+	if opts.Default == nil {
+		opts.Default = NewProbabilisticSampler(defaultSamplingRate)
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &RemoteSampler{
+		log:     log.Named("remote sampler"),
+		opts:    opts,
+		url:     parsedURL.String(),
+		client:  client,
+		sampler: opts.Default,
+	}, nil
+}
+
+// Sample implements Sampler.
+func (s *RemoteSampler) Sample(trace *monkit.Trace, root *monkit.Span) SamplingDecision {
+	return s.current().Sample(trace, root)
+}
+
+func (s *RemoteSampler) current() Sampler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sampler
+}
+
+// Run fetches the sampling strategy on a jittered interval, until ctx is
+// canceled.
+func (s *RemoteSampler) Run(ctx context.Context) {
+	s.log.Debug("started")
+	defer s.log.Debug("stopped")
+
+	if err := s.Update(ctx); err != nil {
+		s.log.Debug("failed to update sampling strategy", zap.Error(err))
+	}
+
+	ticker := time.NewTicker(jitter(s.opts.RefreshInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Update(ctx); err != nil {
+				s.log.Debug("failed to update sampling strategy", zap.Error(err))
+			}
+			ticker.Reset(jitter(s.opts.RefreshInterval))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Update fetches the sampling strategy and swaps the active sampler if the
+// strategy changed.
+func (s *RemoteSampler) Update(ctx context.Context) error {
+	strategy, err := s.fetch(ctx)
+	if err != nil {
+		mon.Counter("jaeger_sampling_strategy_fetch_failure").Inc(1)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.strategy != nil && time.Since(s.lastSuccess) > s.opts.MaxStaleness {
+			s.log.Debug("sampling strategy is stale, falling back to default")
+			s.sampler = s.opts.Default
+			s.strategy = nil
+		}
+		return err
+	}
+
+	sampler, err := strategy.sampler()
+	if err != nil {
+		mon.Counter("jaeger_sampling_strategy_invalid").Inc(1)
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastSuccess = time.Now()
+	// keep the existing sampler when nothing changed, so rate limiters
+	// don't get reset.
+	if s.strategy != nil && reflect.DeepEqual(s.strategy, strategy) {
+		return nil
+	}
+	s.strategy = strategy
+	s.sampler = sampler
+	return nil
+}
+
+func (s *RemoteSampler) fetch(ctx context.Context) (*samplingStrategyResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSamplingResponseSize))
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errs.New("Error on fetching sampling strategy. HTTP %s: %s", resp.Status, string(body))
+	}
+
+	var strategy samplingStrategyResponse
+	if err := json.Unmarshal(body, &strategy); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return &strategy, nil
+}
+
+// samplingStrategyType is the strategy type of the sampling endpoint. Some
+// versions of the agent encode it as a number, others as the enum name.
+type samplingStrategyType int
+
+const (
+	probabilisticStrategy samplingStrategyType = 0
+	rateLimitingStrategy  samplingStrategyType = 1
+)
+
+func (t *samplingStrategyType) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		var value int
+		if err := json.Unmarshal(data, &value); err != nil {
+			return errs.New("invalid strategy type: %s", string(data))
+		}
+		*t = samplingStrategyType(value)
+		return nil
+	}
+
+	switch strings.ToUpper(name) {
+	case "PROBABILISTIC":
+		*t = probabilisticStrategy
+	case "RATE_LIMITING":
+		*t = rateLimitingStrategy
+	default:
+		return errs.New("invalid strategy type: %q", name)
+	}
+	return nil
+}
+
+// samplingStrategyResponse is the response of the sampling endpoint, see
+// https://github.com/jaegertracing/jaeger-idl/blob/main/thrift/sampling.thrift.
+type samplingStrategyResponse struct {
+	StrategyType          samplingStrategyType           `json:"strategyType"`
+	ProbabilisticSampling *probabilisticSamplingStrategy `json:"probabilisticSampling,omitempty"`
+	RateLimitingSampling  *rateLimitingSamplingStrategy  `json:"rateLimitingSampling,omitempty"`
+	OperationSampling     *perOperationSamplingStrategy  `json:"operationSampling,omitempty"`
+}
+
+type probabilisticSamplingStrategy struct {
+	SamplingRate float64 `json:"samplingRate"`
+}
+
+type rateLimitingSamplingStrategy struct {
+	MaxTracesPerSecond float64 `json:"maxTracesPerSecond"`
+}
+
+type operationSamplingStrategy struct {
+	Operation             string                         `json:"operation"`
+	ProbabilisticSampling *probabilisticSamplingStrategy `json:"probabilisticSampling"`
+}
+
+type perOperationSamplingStrategy struct {
+	DefaultSamplingProbability       float64                     `json:"defaultSamplingProbability"`
+	DefaultLowerBoundTracesPerSecond float64                     `json:"defaultLowerBoundTracesPerSecond"`
+	PerOperationStrategies           []operationSamplingStrategy `json:"perOperationStrategies"`
+}
+
+// sampler creates the sampler for the strategy. Per operation strategies take
+// precedence, like in the jaeger clients.
+func (r *samplingStrategyResponse) sampler() (Sampler, error) {
+	if ops := r.OperationSampling; ops != nil {
+		rates := make(map[string]float64, len(ops.PerOperationStrategies))
+		for _, op := range ops.PerOperationStrategies {
+			if op.ProbabilisticSampling == nil {
+				continue
+			}
+			rates[op.Operation] = op.ProbabilisticSampling.SamplingRate
+		}
+		return NewPerOperationSampler(ops.DefaultSamplingProbability, ops.DefaultLowerBoundTracesPerSecond, rates), nil
+	}
+
+	switch r.StrategyType {
+	case probabilisticStrategy:
+		if r.ProbabilisticSampling == nil {
+			return nil, errs.New("missing probabilistic sampling strategy")
+		}
+		return NewProbabilisticSampler(r.ProbabilisticSampling.SamplingRate), nil
+	case rateLimitingStrategy:
+		if r.RateLimitingSampling == nil {
+			return nil, errs.New("missing rate limiting sampling strategy")
+		}
+		return NewRateLimitingSampler(r.RateLimitingSampling.MaxTracesPerSecond), nil
+	default:
+		return nil, errs.New("unsupported strategy type: %d", r.StrategyType)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"storj.io/common/testcontext"
+)
+
+// samplingEndpoint is a stand-in for the sampling endpoint of the agent.
+type samplingEndpoint struct {
+	mu       sync.Mutex
+	service  string
+	status   int
+	response string
+}
+
+func (e *samplingEndpoint) set(status int, response string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.status, e.response = status, response
+}
+
+func (e *samplingEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.service = r.URL.Query().Get("service")
+	w.WriteHeader(e.status)
+	_, _ = w.Write([]byte(e.response))
+}
+
+func TestRemoteSampler(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	endpoint := &samplingEndpoint{}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+
+	sampler, err := NewRemoteSampler(zaptest.NewLogger(t), RemoteSamplerOptions{
+		URL:          server.URL + "/sampling",
+		ServiceName:  "satellite",
+		MaxStaleness: time.Hour,
+		Default:      NeverSample,
+	})
+	require.NoError(t, err)
+
+	// the default is used until the strategy is fetched.
+	require.Equal(t, NeverSample, sampler.current())
+
+	endpoint.set(http.StatusOK, `{"strategyType":"PROBABILISTIC","probabilisticSampling":{"samplingRate":0.5}}`)
+	require.NoError(t, sampler.Update(ctx))
+	require.Equal(t, "satellite", endpoint.service)
+	require.Equal(t, NewProbabilisticSampler(0.5), sampler.current())
+
+	// unchanged strategies keep the sampler.
+	current := sampler.current()
+	require.NoError(t, sampler.Update(ctx))
+	require.Same(t, current, sampler.current())
+
+	endpoint.set(http.StatusOK, `{"strategyType":1,"rateLimitingSampling":{"maxTracesPerSecond":3}}`)
+	require.NoError(t, sampler.Update(ctx))
+	rateLimiting, ok := sampler.current().(*RateLimitingSampler)
+	require.True(t, ok)
+	require.Equal(t, float64(3), rateLimiting.limiter.creditsPerSecond)
+
+	endpoint.set(http.StatusOK, `{
+		"strategyType": "PROBABILISTIC",
+		"operationSampling": {
+			"defaultSamplingProbability": 0.1,
+			"defaultLowerBoundTracesPerSecond": 0.2,
+			"perOperationStrategies": [
+				{"operation": "upload", "probabilisticSampling": {"samplingRate": 1}}
+			]
+		}
+	}`)
+	require.NoError(t, sampler.Update(ctx))
+	perOperation, ok := sampler.current().(*PerOperationSampler)
+	require.True(t, ok)
+	require.Equal(t, 0.1, perOperation.defaultRate)
+	require.Equal(t, 0.2, perOperation.lowerBound)
+	require.Equal(t, float64(1), perOperation.operations["upload"].probabilistic.Rate())
+
+	// invalid responses keep the last strategy.
+	endpoint.set(http.StatusOK, `{"strategyType":"UNKNOWN"}`)
+	require.Error(t, sampler.Update(ctx))
+	require.Same(t, perOperation, sampler.current())
+
+	// failures keep the last strategy until it gets stale.
+	endpoint.set(http.StatusServiceUnavailable, "")
+	require.Error(t, sampler.Update(ctx))
+	require.Same(t, perOperation, sampler.current())
+
+	sampler.opts.MaxStaleness = time.Nanosecond
+	require.Error(t, sampler.Update(ctx))
+	require.Equal(t, NeverSample, sampler.current())
+}
+
+func TestRemoteSamplerUnreachable(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	sampler, err := NewRemoteSampler(zaptest.NewLogger(t), RemoteSamplerOptions{
+		URL:         server.URL,
+		ServiceName: "storagenode",
+	})
+	require.NoError(t, err)
+
+	require.Error(t, sampler.Update(ctx))
+	require.Equal(t, NewProbabilisticSampler(defaultSamplingRate), sampler.current())
+}
@@ -0,0 +1,316 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/monkit-jaeger/gen-go/jaeger"
+)
+
+const (
+	// defaultRetryAttempts is the default number of attempts to send a batch.
+	defaultRetryAttempts = 3
+	// defaultRetryInitialBackoff is the default delay before the first retry.
+	defaultRetryInitialBackoff = time.Second
+	// defaultRetryMaxBackoff is the default upper limit of retry delays.
+	defaultRetryMaxBackoff = 30 * time.Second
+	// defaultRetryQueueBytes is the default size of the retry queue.
+	defaultRetryQueueBytes = 4 << 20
+	// maxDefaultRetryTimeout limits the default time a retry may take.
+	maxDefaultRetryTimeout = 10 * time.Second
+)
+
+// RetryPolicy configures how a ThriftCollector retries batches that failed
+// to send. Failed batches wait in a queue while new spans keep being
+// collected.
+type RetryPolicy struct {
+	// Attempts is the number of attempts to send a batch, including the
+	// first one. Retries are disabled if it's negative or one. Defaults to 3.
+	Attempts int
+	// InitialBackoff is the delay before the first retry. It doubles with
+	// every retry. Defaults to 1s.
+	InitialBackoff time.Duration
+	// MaxBackoff limits the delay between retries. Defaults to 30s.
+	MaxBackoff time.Duration
+	// MaxQueueBytes limits the thrift size of the batches waiting to be
+	// retried. The oldest batches are dropped when it's exceeded. Defaults
+	// to 4MiB.
+	MaxQueueBytes int
+	// Timeout limits how long the collector waits for a retry to be sent.
+	// Retries are sent between handling spans, so spans collected in the
+	// meantime wait in the queue, and are dropped once it's full. A shorter
+	// timeout protects the queue, a longer one lets retries to slow receivers
+	// succeed. Defaults to the flush interval of the collector, but at most
+	// 10s.
+	Timeout time.Duration
+}
+
+// withDefaults fills in the defaults for a collector with the flush interval.
+func (policy RetryPolicy) withDefaults(flushInterval time.Duration) RetryPolicy {
+	if policy.Attempts == 0 {
+		policy.Attempts = defaultRetryAttempts
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = defaultRetryInitialBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaultRetryMaxBackoff
+	}
+	if policy.MaxQueueBytes <= 0 {
+		policy.MaxQueueBytes = defaultRetryQueueBytes
+	}
+	if policy.Timeout <= 0 {
+		policy.Timeout = flushInterval
+		if policy.Timeout <= 0 || policy.Timeout > maxDefaultRetryTimeout {
+			policy.Timeout = maxDefaultRetryTimeout
+		}
+	}
+	return policy
+}
+
+// backoff returns the jittered delay before the given retry, starting at 1.
+func (policy RetryPolicy) backoff(retry int) time.Duration {
+	delay := policy.InitialBackoff
+	for i := 1; i < retry && delay < policy.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > policy.MaxBackoff {
+		delay = policy.MaxBackoff
+	}
+	return jitter(delay)
+}
+
+// RetryAfterError is returned by transports when the receiver asked to retry
+// the batch after a delay.
+type RetryAfterError struct {
+	Delay time.Duration
+	Err   error
+}
+
+// Error implements error.
+func (err *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v (retry after %v)", err.Err, err.Delay)
+}
+
+// Unwrap returns the underlying error.
+func (err *RetryAfterError) Unwrap() error { return err.Err }
+
+// ErrPermanent is the error class of send failures which can't be fixed by
+// retrying.
+var ErrPermanent = errs.Class("permanent")
+
+// parseRetryAfter parses the value of a Retry-After header, which is either
+// in seconds or a date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := date.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// retryBatch is a batch waiting to be retried.
+type retryBatch struct {
+	batch    *jaeger.Batch
+	bytes    int
+	attempts int
+	due      time.Time
+}
+
+// queueRetry schedules a retry of the batch which failed with err, unless
+// the error is permanent or the batch ran out of attempts. It returns false if
+// the batch was dropped. c.mu must be held.
+func (c *ThriftCollector) queueRetry(batch *jaeger.Batch, attempts int, err error, now time.Time) bool {
+	if attempts >= c.retryPolicy.Attempts || c.retryPolicy.Attempts <= 1 ||
+		ErrPermanent.Has(err) || errors.Is(err, ErrBatchTooLarge) {
+		c.failedToEmitSpans.Add(int64(len(batch.Spans)))
+		return false
+	}
+
+	delay := c.retryPolicy.backoff(attempts)
+	var retryAfter *RetryAfterError
+	if errors.As(err, &retryAfter) && retryAfter.Delay > delay {
+		delay = retryAfter.Delay
+	}
+
+	// the spans of the batch are reused by the collector.
+	batch.Spans = append([]*jaeger.Span(nil), batch.Spans...)
+
+	bytes := 0
+	for _, span := range batch.Spans {
+		size, err := calculateThriftSize(span, c.spanSizeBuffer, c.spanSizeProtocol)
+		if err != nil {
+			c.failedToEmitSpans.Add(int64(len(batch.Spans)))
+			return false
+		}
+		bytes += size
+	}
+	if bytes > c.retryPolicy.MaxQueueBytes {
+		c.failedToEmitSpans.Add(int64(len(batch.Spans)))
+		return false
+	}
+
+	// make room by dropping the oldest batches.
+	for c.retryBytes+bytes > c.retryPolicy.MaxQueueBytes {
+		dropped := c.retries[0]
+		c.retries[0] = nil
+		c.retries = c.retries[1:]
+		c.retryBytes -= dropped.bytes
+		c.failedToEmitSpans.Add(int64(len(dropped.batch.Spans)))
+		mon.Counter("jaeger_retry_queue_full").Inc(1)
+	}
+
+	c.retries = append(c.retries, &retryBatch{
+		batch:    batch,
+		bytes:    bytes,
+		attempts: attempts,
+		due:      now.Add(delay),
+	})
+	c.retryBytes += bytes
+	c.retryGeneration.Add(1)
+	mon.Counter("jaeger_batch_retry_queued").Inc(1)
+	return true
+}
+
+// Retry sends the next queued batch which is due at now, or as many as a
+// BatchesTransport sends at once. The collector isn't locked while the
+// batches are sent, and sending is limited by RetryPolicy.Timeout. The
+// batches which are still due are sent by the next calls.
+func (c *ThriftCollector) Retry(ctx context.Context, transport Transport, now time.Time) (err error) {
+	c.mu.Lock()
+	retries := c.takeRetries(func(retry *retryBatch) bool {
+		return !retry.due.After(now)
+	}, retriesPerSend(transport))
+	c.mu.Unlock()
+
+	return c.sendRetries(ctx, transport, retries)
+}
+
+// retriesPerSend returns how many batches the transport sends at once.
+func retriesPerSend(transport Transport) int {
+	if _, ok := transport.(BatchesTransport); ok {
+		return maxBatchesPerSubmit
+	}
+	return 1
+}
+
+// takeRetries removes up to limit queued batches selected by due from the
+// queue, and returns them. limit is ignored if it isn't positive. c.mu must
+// be held.
+func (c *ThriftCollector) takeRetries(due func(*retryBatch) bool, limit int) []*retryBatch {
+	var taken []*retryBatch
+	queued := c.retries
+	c.retries = nil
+	c.retryBytes = 0
+	for i, retry := range queued {
+		queued[i] = nil
+		if !due(retry) || limit > 0 && len(taken) >= limit {
+			c.retries = append(c.retries, retry)
+			c.retryBytes += retry.bytes
+			continue
+		}
+		taken = append(taken, retry)
+	}
+	return taken
+}
+
+// sendRetries sends the batches taken from the queue, and queues the failed
+// ones again. c.mu must not be held, so that sending doesn't block handling
+// new spans.
+func (c *ThriftCollector) sendRetries(ctx context.Context, transport Transport, retries []*retryBatch) (err error) {
+	if len(retries) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.retryPolicy.Timeout)
+	defer cancel()
+
+	batches := make([]*jaeger.Batch, len(retries))
+	stats := c.clientStats()
+	for i, retry := range retries {
+		retry.batch.Stats = stats
+		batches[i] = retry.batch
+	}
+
+	if batchesTransport, ok := transport.(BatchesTransport); ok {
+		err = batchesTransport.SendBatches(ctx, batches)
+	} else {
+		err = transport.Send(ctx, batches[0])
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, batchErr := range batchErrors(err, len(retries)) {
+		if batchErr != nil {
+			c.queueRetry(retries[i].batch, retries[i].attempts+1, batchErr, time.Now())
+			continue
+		}
+		mon.Counter("jaeger_batch_retry_succeeded").Inc(1)
+	}
+	return errs.Wrap(err)
+}
+
+// retryOnClose gives the queued batches a last attempt, without waiting for
+// their backoff.
+func (c *ThriftCollector) retryOnClose(ctx context.Context, transport Transport) {
+	c.mu.Lock()
+	retries := c.takeRetries(func(*retryBatch) bool { return true }, 0)
+	c.mu.Unlock()
+
+	size := retriesPerSend(transport)
+	for len(retries) > 0 {
+		n := size
+		if n > len(retries) {
+			n = len(retries)
+		}
+		if err := c.sendRetries(ctx, transport, retries[:n]); err != nil {
+			c.log.Debug("failed to retry on close", zap.Error(err))
+		}
+		retries = retries[n:]
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dropRetries()
+}
+
+// dropRetries drops the queued batches. c.mu must be held.
+func (c *ThriftCollector) dropRetries() {
+	for _, retry := range c.retries {
+		c.failedToEmitSpans.Add(int64(len(retry.batch.Spans)))
+	}
+	c.retries = nil
+	c.retryBytes = 0
+}
+
+// nextRetry returns when the next queued batch is due.
+func (c *ThriftCollector) nextRetry() (next time.Time, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, retry := range c.retries {
+		if next.IsZero() || retry.due.Before(next) {
+			next = retry.due
+		}
+	}
+	return next, !next.IsZero()
+}
@@ -0,0 +1,277 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap/zaptest"
+
+	"storj.io/common/testcontext"
+	"storj.io/monkit-jaeger/gen-go/jaeger"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 3 * time.Second}.withDefaults(time.Second)
+	require.Equal(t, defaultRetryAttempts, policy.Attempts)
+
+	// the timeout defaults to the flush interval, so retries don't hold up
+	// the spans for longer than a flush would.
+	require.Equal(t, time.Second, policy.Timeout)
+	require.Equal(t, maxDefaultRetryTimeout, RetryPolicy{}.withDefaults(time.Hour).Timeout)
+	for retry, expected := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 3 * time.Second, 100: 3 * time.Second} {
+		// the backoff is jittered with a standard deviation of a quarter.
+		backoff := policy.backoff(retry)
+		require.Greater(t, backoff, time.Duration(0))
+		require.Less(t, backoff, 3*expected)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	delay, ok := parseRetryAfter("120", now)
+	require.True(t, ok)
+	require.Equal(t, 2*time.Minute, delay)
+
+	delay, ok = parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now)
+	require.True(t, ok)
+	require.Equal(t, time.Minute, delay)
+
+	_, ok = parseRetryAfter("", now)
+	require.False(t, ok)
+	_, ok = parseRetryAfter("soon", now)
+	require.False(t, ok)
+}
+
+func TestThriftCollectorRetries(t *testing.T) {
+	ctx := context.Background()
+	collector, err := NewThriftCollector(zaptest.NewLogger(t), "localhost:6831", "test", nil, 0, 0, time.Hour)
+	require.NoError(t, err)
+	collector.SetRetryPolicy(RetryPolicy{Attempts: 3, InitialBackoff: time.Minute})
+
+	transport := &recordingTransport{fail: true}
+	require.NoError(t, collector.handleSpan(ctx, newTestSpan("retried"), transport))
+	require.Error(t, collector.Send(ctx, transport))
+	require.Len(t, collector.retries, 1)
+
+	// nothing is due yet.
+	now := time.Now()
+	require.NoError(t, collector.Retry(ctx, transport, now))
+	require.Len(t, collector.retries, 1)
+
+	// the second attempt fails, the third succeeds.
+	require.Error(t, collector.Retry(ctx, transport, now.Add(time.Hour)))
+	require.Len(t, collector.retries, 1)
+
+	transport.fail = false
+	require.NoError(t, collector.Retry(ctx, transport, now.Add(2*time.Hour)))
+	require.Empty(t, collector.retries)
+	require.Len(t, transport.batches, 1)
+	require.Equal(t, "retried", transport.batches[0].Spans[0].OperationName)
+	require.Zero(t, collector.clientStats().FailedToEmitSpans)
+
+	// batches are dropped after the last attempt.
+	transport.fail = true
+	require.NoError(t, collector.handleSpan(ctx, newTestSpan("dropped"), transport))
+	require.Error(t, collector.Send(ctx, transport))
+	require.Error(t, collector.Retry(ctx, transport, now.Add(time.Hour)))
+	require.Error(t, collector.Retry(ctx, transport, now.Add(2*time.Hour)))
+	require.Empty(t, collector.retries)
+	require.Equal(t, int64(1), collector.clientStats().FailedToEmitSpans)
+
+	// permanent failures aren't retried.
+	require.NoError(t, collector.handleSpan(ctx, newTestSpan("permanent"), transport))
+	require.Error(t, collector.Send(ctx, permanentTransport{}))
+	require.Empty(t, collector.retries)
+	require.Equal(t, int64(2), collector.clientStats().FailedToEmitSpans)
+}
+
+// permanentTransport fails every batch permanently.
+type permanentTransport struct{}
+
+func (permanentTransport) Send(ctx context.Context, batch *jaeger.Batch) error {
+	return ErrPermanent.New("bad request")
+}
+
+func (permanentTransport) Close() {}
+
+func TestThriftCollectorRetryQueueLimit(t *testing.T) {
+	ctx := context.Background()
+	collector, err := NewThriftCollector(zaptest.NewLogger(t), "localhost:6831", "test", nil, 0, 0, time.Hour)
+	require.NoError(t, err)
+
+	// the spans have the same size.
+	newSpan := func() *jaeger.Span {
+		span := newTestSpan("queued")
+		span.TraceIdLow, span.SpanId = 1, 1
+		return span
+	}
+	spanSize, err := calculateThriftSize(newSpan(), collector.spanSizeBuffer, collector.spanSizeProtocol)
+	require.NoError(t, err)
+	collector.SetRetryPolicy(RetryPolicy{MaxQueueBytes: 2 * spanSize})
+
+	transport := &recordingTransport{fail: true}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, collector.handleSpan(ctx, newSpan(), transport))
+		require.Error(t, collector.Send(ctx, transport))
+	}
+
+	// the oldest batch was dropped to make room.
+	require.Len(t, collector.retries, 2)
+	require.Equal(t, int64(2), *collector.retries[0].batch.SeqNo)
+	require.Equal(t, int64(1), collector.clientStats().FailedToEmitSpans)
+}
+
+func TestHTTPTransportRetryAfter(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	var mu sync.Mutex
+	var requests []*jaeger.Batch
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		protocol := thrift.NewTBinaryProtocolConf(thrift.NewStreamTransportR(r.Body), nil)
+		batch := &jaeger.Batch{}
+		require.NoError(t, batch.Read(r.Context(), protocol))
+		requests = append(requests, batch)
+
+		if len(requests) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	collector, err := NewThriftCollector(zaptest.NewLogger(t), server.URL, "test", nil, 0, 0, 10*time.Millisecond)
+	require.NoError(t, err)
+	collector.SetRetryPolicy(RetryPolicy{InitialBackoff: time.Millisecond})
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		collector.Run(runCtx)
+	}()
+
+	// the batch is retried by the running collector.
+	succeeded := mon.Counter("jaeger_batch_retry_succeeded").Current()
+	collector.Collect(newTestSpan("retried"))
+	require.Eventually(t, func() bool {
+		return mon.Counter("jaeger_batch_retry_succeeded").Current() > succeeded
+	}, 5*time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, requests, 2)
+	require.Equal(t, *requests[0].SeqNo, *requests[1].SeqNo)
+	require.Zero(t, collector.clientStats().FailedToEmitSpans)
+}
+
+func TestThriftCollectorBlockedRetry(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	blocked := make(chan struct{}, 1)
+	var mu sync.Mutex
+	var attempts int
+	var collected []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		protocol := thrift.NewTBinaryProtocolConf(thrift.NewStreamTransportR(r.Body), nil)
+		batch := &jaeger.Batch{}
+		require.NoError(t, batch.Read(r.Context(), protocol))
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, span := range batch.Spans {
+			if span.OperationName != "retried" {
+				collected = append(collected, span.OperationName)
+				continue
+			}
+			// the first attempt fails, and the retry blocks until it times
+			// out.
+			attempts++
+			if attempts == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			mu.Unlock()
+			blocked <- struct{}{}
+			<-r.Context().Done()
+			mu.Lock()
+			return
+		}
+	}))
+	defer server.Close()
+
+	collector, err := NewThriftCollector(zaptest.NewLogger(t), server.URL, "test", nil, 0, 0, 10*time.Millisecond)
+	require.NoError(t, err)
+	collector.SetRetryPolicy(RetryPolicy{Attempts: 2, InitialBackoff: time.Millisecond, Timeout: 100 * time.Millisecond})
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		collector.Run(runCtx)
+	}()
+
+	collector.Collect(newTestSpan("retried"))
+	<-blocked
+
+	// the collector isn't locked while the retry is sent, and the spans
+	// collected in the meantime are sent after it timed out.
+	_, queued := collector.nextRetry()
+	require.False(t, queued)
+	for i := 0; i < 10; i++ {
+		collector.Collect(newTestSpan("collected"))
+	}
+	require.Zero(t, collector.clientStats().FullQueueDroppedSpans)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(collected) == 10
+	}, 5*time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+	require.Equal(t, int64(1), collector.clientStats().FailedToEmitSpans)
+}
+
+func TestClassifyHTTPError(t *testing.T) {
+	for status, permanent := range map[int]bool{
+		http.StatusBadRequest:          true,
+		http.StatusRequestTimeout:      false,
+		http.StatusTooManyRequests:     false,
+		http.StatusServiceUnavailable:  false,
+		http.StatusInternalServerError: false,
+	} {
+		resp := &http.Response{StatusCode: status, Header: http.Header{"Retry-After": {"7"}}}
+		err := classifyHTTPError(resp, errs.New("failed"))
+		require.Equal(t, permanent, ErrPermanent.Has(err), status)
+
+		var retryAfter *RetryAfterError
+		require.Equal(t, !permanent, errors.As(err, &retryAfter), status)
+		if !permanent {
+			require.Equal(t, 7*time.Second, retryAfter.Delay)
+		}
+	}
+
+	// batches which are too large are split instead of retried.
+	err := classifyHTTPError(&http.Response{StatusCode: http.StatusRequestEntityTooLarge}, errs.New("failed"))
+	require.ErrorIs(t, err, ErrBatchTooLarge)
+	require.False(t, ErrPermanent.Has(err))
+}
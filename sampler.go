@@ -0,0 +1,274 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/mwc"
+)
+
+const (
+	// SamplerTypeTagKey is the root span tag that records which sampler made
+	// the sampling decision.
+	SamplerTypeTagKey = "sampler.type"
+	// SamplerParamTagKey is the root span tag that records the parameter of
+	// the sampler that made the sampling decision.
+	SamplerParamTagKey = "sampler.param"
+
+	// the sampler types match the ones used by the jaeger clients.
+	samplerTypeConst         = "const"
+	samplerTypeProbabilistic = "probabilistic"
+	samplerTypeRateLimiting  = "ratelimiting"
+	samplerTypeLowerBound    = "lowerbound"
+
+	// defaultMaxOperations limits how many operations PerOperationSampler
+	// keeps individual samplers for.
+	defaultMaxOperations = 2000
+)
+
+// SamplingDecision is the result of a Sampler.
+type SamplingDecision struct {
+	// Sample is whether the trace should be sent to the collector.
+	Sample bool
+	// Tags are added to the root span of sampled traces.
+	Tags []Tag
+}
+
+// Sampler decides whether a new trace should be sent to the collector.
+type Sampler interface {
+	// Sample is called once per trace without a sampling decision, when the
+	// root span starts.
+	Sample(trace *monkit.Trace, root *monkit.Span) SamplingDecision
+}
+
+// needsRoot returns whether the sampler decides based on the root span. The
+// built-in samplers that don't are asked when the trace starts, with a nil
+// root span, so that unsampled traces don't have to be observed.
+func needsRoot(sampler Sampler) bool {
+	switch sampler := sampler.(type) {
+	case *ConstSampler, *ProbabilisticSampler, *RateLimitingSampler:
+		return false
+	case *RemoteSampler:
+		return needsRoot(sampler.current())
+	default:
+		return true
+	}
+}
+
+// SamplerFunc is an adapter that allows using an ordinary function as
+// Sampler.
+type SamplerFunc func(trace *monkit.Trace, root *monkit.Span) SamplingDecision
+
+// Sample implements Sampler.
+func (f SamplerFunc) Sample(trace *monkit.Trace, root *monkit.Span) SamplingDecision {
+	return f(trace, root)
+}
+
+func samplerTags(samplerType string, param interface{}) []Tag {
+	return []Tag{
+		{Key: SamplerTypeTagKey, Value: samplerType},
+		{Key: SamplerParamTagKey, Value: param},
+	}
+}
+
+// ConstSampler always makes the same sampling decision.
+type ConstSampler struct {
+	decision bool
+	tags     []Tag
+}
+
+var (
+	// AlwaysSample samples every trace.
+	AlwaysSample = NewConstSampler(true)
+	// NeverSample samples no trace.
+	NeverSample = NewConstSampler(false)
+)
+
+// NewConstSampler creates a sampler that always returns decision.
+func NewConstSampler(decision bool) *ConstSampler {
+	return &ConstSampler{
+		decision: decision,
+		tags:     samplerTags(samplerTypeConst, decision),
+	}
+}
+
+// Sample implements Sampler.
+func (s *ConstSampler) Sample(*monkit.Trace, *monkit.Span) SamplingDecision {
+	return SamplingDecision{Sample: s.decision, Tags: s.tags}
+}
+
+// ProbabilisticSampler samples the given fraction of traces.
+type ProbabilisticSampler struct {
+	rate float64
+	tags []Tag
+}
+
+// NewProbabilisticSampler creates a sampler that samples traces with the
+// probability rate, which is clamped to [0, 1].
+func NewProbabilisticSampler(rate float64) *ProbabilisticSampler {
+	rate = math.Max(0, math.Min(1, rate))
+	return &ProbabilisticSampler{
+		rate: rate,
+		tags: samplerTags(samplerTypeProbabilistic, rate),
+	}
+}
+
+// Rate returns the sampling probability.
+func (s *ProbabilisticSampler) Rate() float64 { return s.rate }
+
+// Sample implements Sampler.
+func (s *ProbabilisticSampler) Sample(*monkit.Trace, *monkit.Span) SamplingDecision {
+	return SamplingDecision{Sample: s.sample(), Tags: s.tags}
+}
+
+func (s *ProbabilisticSampler) sample() bool {
+	return mwc.Rand().Float64() < s.rate
+}
+
+// RateLimitingSampler samples at most the configured number of traces per
+// second.
+type RateLimitingSampler struct {
+	limiter *rateLimiter
+	tags    []Tag
+}
+
+// NewRateLimitingSampler creates a sampler that samples up to
+// maxTracesPerSecond traces per second. It samples no traces if
+// maxTracesPerSecond isn't positive.
+func NewRateLimitingSampler(maxTracesPerSecond float64) *RateLimitingSampler {
+	return &RateLimitingSampler{
+		limiter: newRateLimiter(maxTracesPerSecond, math.Max(maxTracesPerSecond, 1)),
+		tags:    samplerTags(samplerTypeRateLimiting, maxTracesPerSecond),
+	}
+}
+
+// Sample implements Sampler.
+func (s *RateLimitingSampler) Sample(*monkit.Trace, *monkit.Span) SamplingDecision {
+	return SamplingDecision{Sample: s.limiter.creditsPerSecond > 0 && s.limiter.allow(1), Tags: s.tags}
+}
+
+// PerOperationSampler samples traces probabilistically based on the
+// operation name of the root span, i.e. the full name of its Func. It
+// additionally guarantees a lower bound of sampled traces per second for each
+// operation.
+type PerOperationSampler struct {
+	defaultRate   float64
+	lowerBound    float64
+	maxOperations int
+
+	mu         sync.Mutex
+	operations map[string]*guaranteedThroughputSampler
+}
+
+// NewPerOperationSampler creates a sampler that samples the operations in
+// rates with their configured probability and all other operations with
+// defaultRate. lowerBound is the minimum number of traces per second that are
+// sampled for each operation, regardless of the probability.
+func NewPerOperationSampler(defaultRate, lowerBound float64, rates map[string]float64) *PerOperationSampler {
+	s := &PerOperationSampler{
+		defaultRate:   defaultRate,
+		lowerBound:    lowerBound,
+		maxOperations: defaultMaxOperations,
+		operations:    make(map[string]*guaranteedThroughputSampler, len(rates)),
+	}
+	for operation, rate := range rates {
+		s.operations[operation] = newGuaranteedThroughputSampler(rate, lowerBound)
+	}
+	return s
+}
+
+// Sample implements Sampler.
+func (s *PerOperationSampler) Sample(trace *monkit.Trace, root *monkit.Span) SamplingDecision {
+	operation := root.Func().FullName()
+
+	s.mu.Lock()
+	sampler, ok := s.operations[operation]
+	if !ok && len(s.operations) < s.maxOperations {
+		sampler = newGuaranteedThroughputSampler(s.defaultRate, s.lowerBound)
+		s.operations[operation] = sampler
+		ok = true
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		// too many operations; fall back to the default probability without
+		// the lower bound guarantee.
+		return NewProbabilisticSampler(s.defaultRate).Sample(trace, root)
+	}
+	return sampler.Sample(trace, root)
+}
+
+// guaranteedThroughputSampler samples probabilistically, but falls back to a
+// rate limiter to sample a minimum number of traces per second.
+type guaranteedThroughputSampler struct {
+	probabilistic *ProbabilisticSampler
+	lowerBound    *rateLimiter
+	lowerTags     []Tag
+}
+
+func newGuaranteedThroughputSampler(rate, lowerBound float64) *guaranteedThroughputSampler {
+	probabilistic := NewProbabilisticSampler(rate)
+	return &guaranteedThroughputSampler{
+		probabilistic: probabilistic,
+		lowerBound:    newRateLimiter(lowerBound, math.Max(lowerBound, 1)),
+		lowerTags:     samplerTags(samplerTypeLowerBound, probabilistic.Rate()),
+	}
+}
+
+func (s *guaranteedThroughputSampler) Sample(*monkit.Trace, *monkit.Span) SamplingDecision {
+	if s.probabilistic.sample() {
+		// keep the limiter up to date, so that the lower bound only kicks in
+		// when the probabilistic sampler samples too little.
+		s.lowerBound.allow(1)
+		return SamplingDecision{Sample: true, Tags: s.probabilistic.tags}
+	}
+	if s.lowerBound.creditsPerSecond > 0 && s.lowerBound.allow(1) {
+		return SamplingDecision{Sample: true, Tags: s.lowerTags}
+	}
+	return SamplingDecision{Sample: false, Tags: s.probabilistic.tags}
+}
+
+// rateLimiter is a token bucket rate limiter.
+type rateLimiter struct {
+	creditsPerSecond float64
+	maxBalance       float64
+	now              func() time.Time
+
+	mu       sync.Mutex
+	balance  float64
+	lastTick time.Time
+}
+
+func newRateLimiter(creditsPerSecond, maxBalance float64) *rateLimiter {
+	return &rateLimiter{
+		creditsPerSecond: creditsPerSecond,
+		maxBalance:       maxBalance,
+		balance:          maxBalance,
+		now:              time.Now,
+		lastTick:         time.Now(),
+	}
+}
+
+// allow returns true and withdraws cost from the bucket if it has enough
+// credits.
+func (r *rateLimiter) allow(cost float64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if elapsed := now.Sub(r.lastTick); elapsed > 0 {
+		r.balance = math.Min(r.maxBalance, r.balance+elapsed.Seconds()*r.creditsPerSecond)
+	}
+	r.lastTick = now
+
+	if r.balance >= cost {
+		r.balance -= cost
+		return true
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/stretchr/testify/require"
+
+	"storj.io/monkit-jaeger/gen-go/jaeger"
+)
+
+// memoryCollector keeps all collected spans in memory.
+type memoryCollector struct {
+	mu    sync.Mutex
+	spans []*jaeger.Span
+}
+
+func (c *memoryCollector) Collect(span *jaeger.Span) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.spans = append(c.spans, span)
+}
+
+func (c *memoryCollector) Spans() []*jaeger.Span {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*jaeger.Span(nil), c.spans...)
+}
+
+func TestConstSampler(t *testing.T) {
+	require.True(t, AlwaysSample.Sample(nil, nil).Sample)
+	require.False(t, NeverSample.Sample(nil, nil).Sample)
+	require.Equal(t, samplerTags(samplerTypeConst, true), AlwaysSample.Sample(nil, nil).Tags)
+}
+
+func TestProbabilisticSampler(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		require.True(t, NewProbabilisticSampler(1).Sample(nil, nil).Sample)
+		require.False(t, NewProbabilisticSampler(0).Sample(nil, nil).Sample)
+	}
+	require.Equal(t, float64(1), NewProbabilisticSampler(5).Rate())
+	require.Equal(t, samplerTags(samplerTypeProbabilistic, 0.5), NewProbabilisticSampler(0.5).Sample(nil, nil).Tags)
+}
+
+func TestRateLimitingSampler(t *testing.T) {
+	now := time.Now()
+	sampler := NewRateLimitingSampler(2)
+	sampler.limiter.now = func() time.Time { return now }
+	sampler.limiter.lastTick = now
+
+	require.True(t, sampler.Sample(nil, nil).Sample)
+	require.True(t, sampler.Sample(nil, nil).Sample)
+	require.False(t, sampler.Sample(nil, nil).Sample)
+
+	now = now.Add(500 * time.Millisecond)
+	require.True(t, sampler.Sample(nil, nil).Sample)
+	require.False(t, sampler.Sample(nil, nil).Sample)
+
+	// the balance never exceeds the burst size.
+	now = now.Add(time.Hour)
+	require.True(t, sampler.Sample(nil, nil).Sample)
+	require.True(t, sampler.Sample(nil, nil).Sample)
+	require.False(t, sampler.Sample(nil, nil).Sample)
+
+	// a rate of zero or less never samples.
+	for _, rate := range []float64{0, -1} {
+		sampler := NewRateLimitingSampler(rate)
+		for i := 0; i < 10; i++ {
+			require.False(t, sampler.Sample(nil, nil).Sample)
+		}
+	}
+}
+
+func TestPerOperationSampler(t *testing.T) {
+	r := monkit.NewRegistry()
+	scope := r.Package()
+
+	sampler := NewPerOperationSampler(0, 0, map[string]float64{
+		scope.FuncNamed("hot").FullName(): 1,
+	})
+
+	var decisions []SamplingDecision
+	r.ObserveTraces(func(trace *monkit.Trace) {
+		trace.ObserveSpans(spanFinishObserverFunc(func(s *monkit.Span, err error, panicked bool, finish time.Time) {
+			decisions = append(decisions, sampler.Sample(trace, s))
+		}))
+	})
+
+	func() { defer scope.FuncNamed("hot").Task(nil)(nil) }()
+	func() { defer scope.FuncNamed("other").Task(nil)(nil) }()
+
+	require.Len(t, decisions, 2)
+	require.True(t, decisions[0].Sample)
+	require.False(t, decisions[1].Sample)
+
+	// the lower bound samples operations even when the probability is zero.
+	sampler = NewPerOperationSampler(0, 1, nil)
+	decisions = nil
+	func() { defer scope.FuncNamed("lower").Task(nil)(nil) }()
+	require.Len(t, decisions, 1)
+	require.True(t, decisions[0].Sample)
+	require.Equal(t, samplerTags(samplerTypeLowerBound, float64(0)), decisions[0].Tags)
+}
+
+func TestRegisterJaegerSampler(t *testing.T) {
+	r := monkit.NewRegistry()
+	collector := &memoryCollector{}
+
+	var roots []string
+	unregister := RegisterJaeger(r, collector, Options{
+		Sampler: SamplerFunc(func(trace *monkit.Trace, root *monkit.Span) SamplingDecision {
+			roots = append(roots, root.Func().ShortName())
+			return SamplingDecision{
+				Sample: root.Func().ShortName() == "sampled",
+				Tags:   samplerTags("custom", 42),
+			}
+		}),
+	})
+	defer unregister()
+
+	scope := r.Package()
+	func() {
+		ctx := context.Background()
+		defer scope.FuncNamed("sampled").Task(&ctx)(nil)
+		defer scope.FuncNamed("child").Task(&ctx)(nil)
+	}()
+	func() {
+		ctx := context.Background()
+		defer scope.FuncNamed("dropped").Task(&ctx)(nil)
+		defer scope.FuncNamed("child").Task(&ctx)(nil)
+	}()
+
+	require.Equal(t, []string{"sampled", "dropped"}, roots)
+
+	spans := collector.Spans()
+	require.Len(t, spans, 2)
+
+	child, root := spans[0], spans[1]
+	require.Contains(t, root.OperationName, "sampled")
+	require.Empty(t, child.Tags)
+
+	tag, ok := findTag(SamplerTypeTagKey, root)
+	require.True(t, ok)
+	require.Equal(t, "custom", tag.GetVStr())
+	tag, ok = findTag(SamplerParamTagKey, root)
+	require.True(t, ok)
+	require.Equal(t, int64(42), tag.GetVLong())
+}
+
+func TestRegisterJaegerSamplesEagerly(t *testing.T) {
+	r := monkit.NewRegistry()
+	collector := &memoryCollector{}
+
+	sampler := NewConstSampler(false)
+	unregister := RegisterJaeger(r, collector, Options{Sampler: sampler})
+	defer unregister()
+
+	// samplers which don't need the root span decide when the trace starts,
+	// so unsampled traces aren't observed.
+	func() {
+		ctx := context.Background()
+		defer r.Package().FuncNamed("dropped").Task(&ctx)(nil)
+		trace := monkit.SpanFromCtx(ctx).Trace()
+		require.Equal(t, false, trace.Get(Sampled))
+		require.Nil(t, trace.Get(samplingKey{}))
+	}()
+	require.Empty(t, collector.Spans())
+
+	// the sampler tags go to the local root.
+	sampler.decision = true
+	func() {
+		ctx := context.Background()
+		defer r.Package().FuncNamed("sampled").Task(&ctx)(nil)
+		defer r.Package().FuncNamed("child").Task(&ctx)(nil)
+	}()
+	spans := collector.Spans()
+	require.Len(t, spans, 2)
+	require.Empty(t, spans[0].Tags)
+	tag, ok := findTag(SamplerTypeTagKey, spans[1])
+	require.True(t, ok)
+	require.Equal(t, samplerTypeConst, tag.GetVStr())
+}
+
+func TestRegisterJaegerRemoteSampledSkipsSampler(t *testing.T) {
+	r := monkit.NewRegistry()
+	collector := &memoryCollector{}
+
+	unregister := RegisterJaeger(r, collector, Options{Sampler: NeverSample})
+	defer unregister()
+
+	trace := monkit.NewTrace(monkit.NewId())
+	trace.Set(Sampled, true)
+
+	func() {
+		ctx := context.Background()
+		defer r.Package().Func().RemoteTrace(&ctx, monkit.NewId(), trace)(nil)
+	}()
+
+	spans := collector.Spans()
+	require.Len(t, spans, 1)
+	_, ok := findTag(SamplerTypeTagKey, spans[0])
+	require.False(t, ok)
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"storj.io/monkit-jaeger/gen-go/jaeger"
+)
+
+const (
+	// TruncatedTagKey is the tag that marks spans that were shrunk to fit
+	// into a packet.
+	TruncatedTagKey = "truncated"
+
+	// truncatedMarker is appended to truncated tag values.
+	truncatedMarker = "...(truncated)"
+
+	// maxTruncatedValueLength is the length long tag values are truncated to
+	// when a span is shrunk.
+	maxTruncatedValueLength = 256
+)
+
+// protectedTags are never dropped when a span is shrunk.
+var protectedTags = map[string]bool{
+	"error":            true,
+	"status":           true,
+	ErrorKindTag:       true,
+	SpanKindTag:        true,
+	TruncatedTagKey:    true,
+	SamplerTypeTagKey:  true,
+	SamplerParamTagKey: true,
+}
+
+// shrinkSpan tries to make the span fit into maxBytes. Long tag values are
+// truncated first, then the logs are dropped, and then the tags which aren't
+// protected, starting with the last one. The identity and timing of the span
+// are always kept. It returns the shrunk span and its size, or nil if the span
+// can't fit.
+func (c *ThriftCollector) shrinkSpan(s *jaeger.Span, maxBytes int) (*jaeger.Span, int, error) {
+	shrunk := *s
+	shrunk.Tags = make([]*jaeger.Tag, 0, len(s.Tags)+1)
+	for _, tag := range s.Tags {
+		if tag.Key == TruncatedTagKey {
+			continue
+		}
+		shrunk.Tags = append(shrunk.Tags, truncateTag(tag))
+	}
+	truncated := true
+	shrunk.Tags = append(shrunk.Tags, &jaeger.Tag{
+		Key:   TruncatedTagKey,
+		VType: jaeger.TagType_BOOL,
+		VBool: &truncated,
+	})
+
+	fits := func() (int, bool, error) {
+		size, err := calculateThriftSize(&shrunk, c.spanSizeBuffer, c.spanSizeProtocol)
+		return size, err == nil && size <= maxBytes, err
+	}
+
+	size, ok, err := fits()
+	if err != nil || ok {
+		return &shrunk, size, err
+	}
+
+	shrunk.Logs = nil
+	size, ok, err = fits()
+	if err != nil || ok {
+		return &shrunk, size, err
+	}
+
+	for i := len(shrunk.Tags) - 1; i >= 0; i-- {
+		if protectedTags[shrunk.Tags[i].Key] {
+			continue
+		}
+		shrunk.Tags = append(shrunk.Tags[:i], shrunk.Tags[i+1:]...)
+
+		size, ok, err = fits()
+		if err != nil || ok {
+			return &shrunk, size, err
+		}
+	}
+
+	return nil, size, nil
+}
+
+// truncateTag returns the tag with its string or binary value truncated.
+func truncateTag(tag *jaeger.Tag) *jaeger.Tag {
+	switch {
+	case tag.VStr != nil && len(*tag.VStr) > maxTruncatedValueLength+len(truncatedMarker):
+		truncated := *tag
+		value := truncateUTF8(*tag.VStr, maxTruncatedValueLength) + truncatedMarker
+		truncated.VStr = &value
+		return &truncated
+	case len(tag.VBinary) > maxTruncatedValueLength:
+		truncated := *tag
+		truncated.VBinary = tag.VBinary[:maxTruncatedValueLength]
+		return &truncated
+	default:
+		return tag
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"storj.io/monkit-jaeger/gen-go/jaeger"
+)
+
+func TestThriftCollectorShrinksSpans(t *testing.T) {
+	ctx := context.Background()
+	collector, err := NewThriftCollector(zaptest.NewLogger(t), "localhost:6831", "test", nil, 0, 0, time.Hour)
+	require.NoError(t, err)
+
+	send := func(span *jaeger.Span) *jaeger.Span {
+		transport := &recordingTransport{}
+		require.NoError(t, collector.handleSpan(ctx, span, transport))
+		require.NoError(t, collector.Send(ctx, transport))
+		require.Len(t, transport.batches, 1)
+		require.Len(t, transport.batches[0].Spans, 1)
+		return transport.batches[0].Spans[0]
+	}
+
+	// long values are truncated.
+	span := newTestSpan("long")
+	span.Tags = NewJaegerTags([]Tag{{Key: "long", Value: strings.Repeat("x", 2000)}, NewErrorTag()})
+	shrunk := send(span)
+	require.Equal(t, span.SpanId, shrunk.SpanId)
+	tag, ok := findTag("long", shrunk)
+	require.True(t, ok)
+	require.Equal(t, strings.Repeat("x", maxTruncatedValueLength)+truncatedMarker, tag.GetVStr())
+	tag, ok = findTag(TruncatedTagKey, shrunk)
+	require.True(t, ok)
+	require.True(t, tag.GetVBool())
+
+	// logs are dropped before tags.
+	span = newTestSpan("logs")
+	span.Tags = NewJaegerTags([]Tag{{Key: "small", Value: "value"}})
+	for i := 0; i < 10; i++ {
+		span.Logs = append(span.Logs, newJaegerLogs(time.Now(), Tag{Key: "event", Value: strings.Repeat("x", 200)})...)
+	}
+	shrunk = send(span)
+	require.Empty(t, shrunk.Logs)
+	_, ok = findTag("small", shrunk)
+	require.True(t, ok)
+
+	// then the tags, except for the protected ones.
+	span = newTestSpan("tags")
+	var tags []Tag
+	for i := 0; i < 20; i++ {
+		tags = append(tags, Tag{Key: fmt.Sprintf("tag-%d", i), Value: strings.Repeat("x", 100)})
+	}
+	tags = append(tags, NewErrorTag(), Tag{Key: "status", Value: "errored"})
+	span.Tags = NewJaegerTags(tags)
+	shrunk = send(span)
+	require.Equal(t, span.TraceIdLow, shrunk.TraceIdLow)
+	require.Equal(t, span.StartTime, shrunk.StartTime)
+	require.Equal(t, span.Duration, shrunk.Duration)
+	require.Less(t, len(shrunk.Tags), len(span.Tags))
+	_, ok = findTag("tag-0", shrunk)
+	require.True(t, ok)
+	_, ok = findTag("tag-19", shrunk)
+	require.False(t, ok)
+	for _, key := range []string{"error", "status", TruncatedTagKey} {
+		_, ok = findTag(key, shrunk)
+		require.True(t, ok, key)
+	}
+
+	require.Zero(t, collector.clientStats().TooLargeDroppedSpans)
+}
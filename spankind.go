@@ -0,0 +1,70 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import "github.com/spacemonkeygo/monkit/v3"
+
+const (
+	// SpanKindTag is the tag that distinguishes server and client spans.
+	SpanKindTag = "span.kind"
+	// SpanKindServer is the kind of spans handling a remote request.
+	SpanKindServer = "server"
+	// SpanKindClient is the kind of spans sending a remote request.
+	SpanKindClient = "client"
+
+	// jaeger span flags, see jaeger-client-go.
+	flagSampled = 0x01
+	flagDebug   = 0x02
+)
+
+type localRootKey struct{}
+
+type debugKey struct{}
+
+// SetSpanKind records the kind of the span, unless it already has one.
+func SetSpanKind(span *monkit.Span, kind string) {
+	if _, ok := spanKind(span); ok {
+		return
+	}
+	span.Annotate(SpanKindTag, kind)
+}
+
+func spanKind(span *monkit.Span) (string, bool) {
+	for _, annotation := range span.Annotations() {
+		if annotation.Name == SpanKindTag {
+			return annotation.Value, true
+		}
+	}
+	return "", false
+}
+
+// markLocalRoot remembers the first span of the trace in this process.
+func markLocalRoot(trace *monkit.Trace, span *monkit.Span) {
+	trace.Set(localRootKey{}, span.Id())
+}
+
+// isLocalRoot returns whether the span is the first span of the trace in this
+// process.
+func isLocalRoot(span *monkit.Span) bool {
+	root, ok := span.Trace().Get(localRootKey{}).(int64)
+	return ok && root == span.Id()
+}
+
+// hasRemoteParent returns whether the span continues a trace of another
+// process.
+func hasRemoteParent(span *monkit.Span) bool {
+	_, hasParent := span.ParentId()
+	return hasParent && isLocalRoot(span)
+}
+
+// setDebug marks the trace as forced to be sampled by the remote side.
+func setDebug(trace *monkit.Trace) {
+	trace.Set(Sampled, true)
+	trace.Set(debugKey{}, true)
+}
+
+func isDebug(trace *monkit.Trace) bool {
+	debug, _ := trace.Get(debugKey{}).(bool)
+	return debug
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/stretchr/testify/require"
+
+	"storj.io/monkit-jaeger/gen-go/jaeger"
+)
+
+func TestSpanKindAndReferences(t *testing.T) {
+	r := monkit.NewRegistry()
+	scope := r.ScopeNamed("test")
+	collector := &memoryCollector{}
+	defer RegisterJaeger(r, collector, Options{Fraction: 1})()
+
+	trace, parentID := UberPropagator{}.Extract(MapCarrier{
+		UberTraceIDHeader: "4bf92f3577b34da6a3ce929d0e0e4736:00f067aa0ba902b7:0:3",
+	})
+	require.NotNil(t, trace)
+
+	func() {
+		ctx := context.Background()
+		defer scope.FuncNamed("server").RemoteTrace(&ctx, parentID, trace)(nil)
+
+		// injecting doesn't change the kind of the span.
+		require.NotNil(t, InjectRemoteInfo(ctx))
+
+		func() {
+			ctx := ctx
+			defer scope.FuncNamed("client").Task(&ctx)(nil)
+			SetSpanKind(monkit.SpanFromCtx(ctx), SpanKindClient)
+			carrier := MapCarrier{}
+			require.True(t, CompositePropagator{RemoteInfoPropagator{}, B3Propagator{}}.Inject(ctx, carrier))
+			require.Equal(t, "1", carrier[B3FlagsHeader])
+		}()
+
+		func() {
+			ctx := ctx
+			defer scope.FuncNamed("local").Task(&ctx)(nil)
+			// only the rpc clients mark their spans, injecting doesn't.
+			require.True(t, UberPropagator{}.Inject(ctx, MapCarrier{}))
+		}()
+	}()
+
+	spans := map[string]*jaeger.Span{}
+	for _, span := range collector.Spans() {
+		spans[span.OperationName] = span
+	}
+	require.Len(t, spans, 3)
+
+	server := spans["test.server"]
+	require.Equal(t, NewJaegerTags([]Tag{{Key: SpanKindTag, Value: SpanKindServer}}), server.Tags)
+	require.Equal(t, []*jaeger.SpanRef{{
+		RefType:     jaeger.SpanRefType_CHILD_OF,
+		TraceIdLow:  trace.Id(),
+		TraceIdHigh: 0x4bf92f3577b34da6,
+		SpanId:      parentID,
+	}}, server.References)
+	require.Equal(t, int32(flagSampled|flagDebug), server.Flags)
+
+	client := spans["test.client"]
+	require.Equal(t, NewJaegerTags([]Tag{{Key: SpanKindTag, Value: SpanKindClient}}), client.Tags)
+	require.Equal(t, server.SpanId, client.ParentSpanId)
+	require.Equal(t, server.SpanId, client.References[0].SpanId)
+
+	local := spans["test.local"]
+	require.Empty(t, local.Tags)
+	require.Equal(t, server.SpanId, local.References[0].SpanId)
+}
+
+func TestSpanKindNewTrace(t *testing.T) {
+	r := monkit.NewRegistry()
+	collector := &memoryCollector{}
+	defer RegisterJaeger(r, collector, Options{Sampler: NewConstSampler(true)})()
+
+	func() {
+		ctx := context.Background()
+		defer r.ScopeNamed("test").FuncNamed("root").Task(&ctx)(nil)
+	}()
+
+	spans := collector.Spans()
+	require.Len(t, spans, 1)
+	require.Empty(t, spans[0].References)
+	require.Equal(t, int32(flagSampled), spans[0].Flags)
+	_, ok := findTag(SpanKindTag, spans[0])
+	require.False(t, ok)
+}
@@ -4,43 +4,196 @@
 package jaeger
 
 import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
 	"github.com/zeebo/errs"
 
 	"storj.io/monkit-jaeger/gen-go/jaeger"
 )
 
+// maxTagEncoderDepth limits how many times registered encoders may hand a
+// value over to another encoder before we give up.
+const maxTagEncoderDepth = 8
+
 // Tag is a key/value pair that allows us to translate monkit annotations and arguments into jaeger thrift tags.
 type Tag struct {
 	Key   string
 	Value interface{}
 }
 
+// TagEncoder converts a value into another value that can be encoded as a
+// jaeger tag, e.g. a string, a number or a []byte.
+type TagEncoder func(value interface{}) (interface{}, error)
+
+var tagEncoders sync.Map // map[reflect.Type]TagEncoder
+
+// RegisterTagEncoder registers enc to be used for all values with the same
+// dynamic type as example. Registered encoders take precedence over the
+// built-in conversions. Passing a nil enc removes the registration.
+func RegisterTagEncoder(example interface{}, enc TagEncoder) {
+	typ := reflect.TypeOf(example)
+	if enc == nil {
+		tagEncoders.Delete(typ)
+		return
+	}
+	tagEncoders.Store(typ, enc)
+}
+
 // BuildJaegerThrift converts tag into jaeger thrift format.
 func (t *Tag) BuildJaegerThrift() (*jaeger.Tag, error) {
 	jaegerTag := &jaeger.Tag{
 		Key: t.Key,
 	}
 
-	switch v := t.Value.(type) {
+	if err := encodeTagValue(jaegerTag, t.Value, 0); err != nil {
+		return nil, errs.New("tag %q: %v", t.Key, err)
+	}
+
+	return jaegerTag, nil
+}
+
+// encodeTagValue sets the value fields of jaegerTag based on value.
+func encodeTagValue(jaegerTag *jaeger.Tag, value interface{}, depth int) error {
+	if depth > maxTagEncoderDepth {
+		return errs.New("tag encoders nested too deeply for %T", value)
+	}
+
+	if enc, ok := tagEncoders.Load(reflect.TypeOf(value)); ok {
+		encoded, err := enc.(TagEncoder)(value)
+		if err != nil {
+			return err
+		}
+		return encodeTagValue(jaegerTag, encoded, depth+1)
+	}
+
+	// the methods of nil pointers with value receivers panic.
+	switch value.(type) {
+	case error, fmt.Stringer:
+		if isNilPointer(value) {
+			setStringTag(jaegerTag, "<nil>")
+			return nil
+		}
+	}
+
+	switch v := value.(type) {
 	case string:
-		jaegerTag.VStr = &v
-		jaegerTag.VType = jaeger.TagType_STRING
+		setStringTag(jaegerTag, v)
 	case bool:
 		jaegerTag.VBool = &v
 		jaegerTag.VType = jaeger.TagType_BOOL
-	case int, int32, int64:
-		num := t.Value.(int64)
-		jaegerTag.VLong = &num
-		jaegerTag.VType = jaeger.TagType_LONG
-	case float32, float64:
-		num := t.Value.(float64)
-		jaegerTag.VDouble = &num
-		jaegerTag.VType = jaeger.TagType_DOUBLE
+	case int:
+		setLongTag(jaegerTag, int64(v))
+	case int8:
+		setLongTag(jaegerTag, int64(v))
+	case int16:
+		setLongTag(jaegerTag, int64(v))
+	case int32:
+		setLongTag(jaegerTag, int64(v))
+	case int64:
+		setLongTag(jaegerTag, v)
+	case uint:
+		setUnsignedTag(jaegerTag, uint64(v))
+	case uint8:
+		setUnsignedTag(jaegerTag, uint64(v))
+	case uint16:
+		setUnsignedTag(jaegerTag, uint64(v))
+	case uint32:
+		setUnsignedTag(jaegerTag, uint64(v))
+	case uint64:
+		setUnsignedTag(jaegerTag, v)
+	case uintptr:
+		setUnsignedTag(jaegerTag, uint64(v))
+	case float32:
+		setDoubleTag(jaegerTag, float64(v))
+	case float64:
+		setDoubleTag(jaegerTag, v)
+	case []byte:
+		jaegerTag.VBinary = v
+		jaegerTag.VType = jaeger.TagType_BINARY
+	case time.Duration:
+		setStringTag(jaegerTag, v.String())
+	case time.Time:
+		setStringTag(jaegerTag, v.UTC().Format(time.RFC3339Nano))
+	case error:
+		setStringTag(jaegerTag, v.Error())
+	case fmt.Stringer:
+		setStringTag(jaegerTag, v.String())
+	case nil:
+		return errs.New("nil value")
 	default:
-		return nil, errs.New("illegal type value: %T", t.Value)
+		return encodeReflectedTagValue(jaegerTag, reflect.ValueOf(value), depth)
 	}
 
-	return jaegerTag, nil
+	return nil
+}
+
+func isNilPointer(value interface{}) bool {
+	rv := reflect.ValueOf(value)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// encodeReflectedTagValue handles named types based on the builtin kinds,
+// e.g. `type Status int`, and pointers to encodable values.
+func encodeReflectedTagValue(jaegerTag *jaeger.Tag, rv reflect.Value, depth int) error {
+	switch rv.Kind() {
+	case reflect.String:
+		setStringTag(jaegerTag, rv.String())
+	case reflect.Bool:
+		v := rv.Bool()
+		jaegerTag.VBool = &v
+		jaegerTag.VType = jaeger.TagType_BOOL
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		setLongTag(jaegerTag, rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		setUnsignedTag(jaegerTag, rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		setDoubleTag(jaegerTag, rv.Float())
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() != reflect.Uint8 {
+			return errs.New("illegal type value: %s", rv.Type())
+		}
+		jaegerTag.VBinary = rv.Bytes()
+		jaegerTag.VType = jaeger.TagType_BINARY
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return errs.New("nil value of type %s", rv.Type())
+		}
+		return encodeTagValue(jaegerTag, rv.Elem().Interface(), depth+1)
+	default:
+		return errs.New("illegal type value: %s", rv.Type())
+	}
+	return nil
+}
+
+func setStringTag(jaegerTag *jaeger.Tag, v string) {
+	jaegerTag.VStr = &v
+	jaegerTag.VType = jaeger.TagType_STRING
+}
+
+func setLongTag(jaegerTag *jaeger.Tag, v int64) {
+	jaegerTag.VLong = &v
+	jaegerTag.VType = jaeger.TagType_LONG
+}
+
+func setDoubleTag(jaegerTag *jaeger.Tag, v float64) {
+	jaegerTag.VDouble = &v
+	jaegerTag.VType = jaeger.TagType_DOUBLE
+}
+
+// setUnsignedTag stores v as a long when it fits, otherwise it falls back to
+// the decimal string representation to avoid wrapping around to a negative
+// number.
+func setUnsignedTag(jaegerTag *jaeger.Tag, v uint64) {
+	if v > math.MaxInt64 {
+		setStringTag(jaegerTag, strconv.FormatUint(v, 10))
+		return
+	}
+	setLongTag(jaegerTag, int64(v))
 }
 
 // NewJaegerTags converts Tag into jaeger format.
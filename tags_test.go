@@ -0,0 +1,136 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/monkit-jaeger/gen-go/jaeger"
+)
+
+type testStatus int
+
+type testStringer struct{}
+
+func (testStringer) String() string { return "stringer" }
+
+type testError struct{}
+
+func (testError) Error() string { return "test error" }
+
+type testPoint struct{ X, Y int }
+
+func TestBuildJaegerThrift(t *testing.T) {
+	str := func(v string) *jaeger.Tag {
+		return &jaeger.Tag{Key: "k", VType: jaeger.TagType_STRING, VStr: &v}
+	}
+	long := func(v int64) *jaeger.Tag {
+		return &jaeger.Tag{Key: "k", VType: jaeger.TagType_LONG, VLong: &v}
+	}
+	double := func(v float64) *jaeger.Tag {
+		return &jaeger.Tag{Key: "k", VType: jaeger.TagType_DOUBLE, VDouble: &v}
+	}
+	boolean := func(v bool) *jaeger.Tag {
+		return &jaeger.Tag{Key: "k", VType: jaeger.TagType_BOOL, VBool: &v}
+	}
+
+	stamp := time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)
+	var nilPtr *int
+	seven := 7
+
+	testcases := []struct {
+		value    interface{}
+		expected *jaeger.Tag
+	}{
+		{"value", str("value")},
+		{true, boolean(true)},
+		{int(1), long(1)},
+		{int8(-2), long(-2)},
+		{int16(3), long(3)},
+		{int32(-4), long(-4)},
+		{int64(5), long(5)},
+		{uint(6), long(6)},
+		{uint8(7), long(7)},
+		{uint16(8), long(8)},
+		{uint32(9), long(9)},
+		{uint64(math.MaxInt64), long(math.MaxInt64)},
+		{uint64(math.MaxUint64), str("18446744073709551615")},
+		{float32(0.5), double(0.5)},
+		{float64(1.5), double(1.5)},
+		{[]byte{1, 2}, &jaeger.Tag{Key: "k", VType: jaeger.TagType_BINARY, VBinary: []byte{1, 2}}},
+		{1500 * time.Millisecond, str("1.5s")},
+		{stamp, str("2020-01-02T03:04:05.000000006Z")},
+		{errors.New("failure"), str("failure")},
+		{testStringer{}, str("stringer")},
+		{(*testStringer)(nil), str("<nil>")},
+		{(*testError)(nil), str("<nil>")},
+		{testStatus(3), long(3)},
+		{&seven, long(7)},
+		{nilPtr, nil},
+		{nil, nil},
+		{testPoint{}, nil},
+		{[]int{1}, nil},
+	}
+
+	for _, tc := range testcases {
+		tag := Tag{Key: "k", Value: tc.value}
+		actual, err := tag.BuildJaegerThrift()
+		if tc.expected == nil {
+			require.Error(t, err, "%T", tc.value)
+			continue
+		}
+		require.NoError(t, err, "%T", tc.value)
+		require.Equal(t, tc.expected, actual, "%T", tc.value)
+	}
+}
+
+func TestRegisterTagEncoder(t *testing.T) {
+	RegisterTagEncoder(testPoint{}, func(value interface{}) (interface{}, error) {
+		p := value.(testPoint)
+		return time.Duration(p.X+p.Y) * time.Second, nil
+	})
+	defer RegisterTagEncoder(testPoint{}, nil)
+
+	tag := Tag{Key: "point", Value: testPoint{X: 1, Y: 2}}
+	actual, err := tag.BuildJaegerThrift()
+	require.NoError(t, err)
+	require.Equal(t, jaeger.TagType_STRING, actual.VType)
+	require.Equal(t, "3s", actual.GetVStr())
+
+	// registered encoders take precedence over the builtin conversions.
+	RegisterTagEncoder(testStatus(0), func(value interface{}) (interface{}, error) {
+		return "status-" + time.Duration(value.(testStatus)).String(), nil
+	})
+	defer RegisterTagEncoder(testStatus(0), nil)
+
+	tag = Tag{Key: "status", Value: testStatus(0)}
+	actual, err = tag.BuildJaegerThrift()
+	require.NoError(t, err)
+	require.Equal(t, "status-0s", actual.GetVStr())
+
+	// encoders that never produce an encodable value are rejected.
+	type loop struct{}
+	RegisterTagEncoder(loop{}, func(value interface{}) (interface{}, error) { return value, nil })
+	defer RegisterTagEncoder(loop{}, nil)
+
+	tag = Tag{Key: "loop", Value: loop{}}
+	_, err = tag.BuildJaegerThrift()
+	require.Error(t, err)
+}
+
+func TestNewJaegerTagsSkipsInvalid(t *testing.T) {
+	tags := NewJaegerTags([]Tag{
+		{Key: "a", Value: 1},
+		{Key: "b", Value: struct{}{}},
+		{Key: "c", Value: float32(2)},
+	})
+	require.Len(t, tags, 2)
+	require.Equal(t, "a", tags[0].Key)
+	require.Equal(t, "c", tags[1].Key)
+}
@@ -0,0 +1,235 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+
+	"storj.io/monkit-jaeger/gen-go/jaeger"
+)
+
+const (
+	// defaultMaxPendingSpans is the default number of spans the tail sampler
+	// buffers.
+	defaultMaxPendingSpans = 10000
+
+	// defaultMaxPendingBytes is the default estimated memory the tail sampler
+	// uses for buffered spans.
+	defaultMaxPendingBytes = 16 << 20
+
+	// spanMemoryOverhead is a rough estimate of the memory used by a jaeger
+	// span and a tag, excluding the variable length fields.
+	spanMemoryOverhead = 160
+	tagMemoryOverhead  = 80
+
+	samplerTypeTail = "tail"
+)
+
+// TailSamplingOptions configures tail-based sampling. Traces that weren't
+// sampled up front are buffered in memory until their local root span
+// finishes. Traces with a panicked or errored span, or with a slow root span
+// are kept, the rest are dropped.
+type TailSamplingOptions struct {
+	// SlowThreshold keeps traces whose local root span took longer. Zero
+	// disables keeping slow traces.
+	SlowThreshold time.Duration
+
+	// MaxPendingSpans limits the number of buffered spans. The oldest pending
+	// traces are evicted when it's exceeded. Defaults to 10000.
+	MaxPendingSpans int
+	// MaxPendingBytes limits the estimated memory of buffered spans. The
+	// oldest pending traces are evicted when it's exceeded. Defaults to 16MiB.
+	MaxPendingBytes int
+}
+
+// tailSampler buffers the finished spans of pending traces.
+type tailSampler struct {
+	srv  *service
+	opts TailSamplingOptions
+
+	mu      sync.Mutex
+	pending *list.List // *pendingTrace, oldest first
+	spans   int
+	bytes   int
+}
+
+// pendingTrace is the state of a trace that wasn't decided yet.
+type pendingTrace struct {
+	trace  *monkit.Trace
+	rootID int64
+
+	elem    *list.Element // nil once the trace is decided or evicted
+	keep    bool
+	errored bool
+	spans   []*jaeger.Span
+	bytes   int
+}
+
+func newTailSampler(srv *service, opts TailSamplingOptions) *tailSampler {
+	if opts.MaxPendingSpans <= 0 {
+		opts.MaxPendingSpans = defaultMaxPendingSpans
+	}
+	if opts.MaxPendingBytes <= 0 {
+		opts.MaxPendingBytes = defaultMaxPendingBytes
+	}
+	return &tailSampler{
+		srv:     srv,
+		opts:    opts,
+		pending: list.New(),
+	}
+}
+
+// track starts buffering the spans of trace until root finishes.
+func (ts *tailSampler) track(trace *monkit.Trace, root *monkit.Span) *pendingTrace {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	pending := &pendingTrace{
+		trace:  trace,
+		rootID: root.Id(),
+	}
+	pending.elem = ts.pending.PushBack(pending)
+	return pending
+}
+
+// finish buffers the span, or makes the decision if it's the root span.
+func (ts *tailSampler) finish(pending *pendingTrace, s *monkit.Span, spanErr error, panicked bool, finish time.Time) {
+	status := spanStatus(spanErr, panicked)
+	errored := status == "errored" || status == "panicked"
+
+	if s.Id() != pending.rootID {
+		ts.mu.Lock()
+		if pending.elem == nil {
+			keep := pending.keep
+			ts.mu.Unlock()
+			// the trace was already decided.
+			if keep {
+				ts.srv.observeSpan(s, spanErr, panicked, finish)
+			}
+			return
+		}
+		pending.errored = pending.errored || errored
+		ts.mu.Unlock()
+
+		js := ts.srv.newJaegerSpan(s, spanErr, panicked, finish)
+		if js == nil {
+			return
+		}
+
+		ts.mu.Lock()
+		buffered := pending.elem != nil
+		if buffered {
+			ts.buffer(pending, js)
+		}
+		keep := pending.keep
+		ts.mu.Unlock()
+
+		// the trace could have been decided while converting the span.
+		if !buffered && keep {
+			ts.collect(pending.trace, []*jaeger.Span{js})
+		}
+		return
+	}
+
+	ts.mu.Lock()
+	if pending.elem == nil {
+		// the trace was evicted.
+		ts.mu.Unlock()
+		return
+	}
+
+	var reason string
+	switch {
+	case errored || pending.errored:
+		reason = "errored"
+	case ts.opts.SlowThreshold > 0 && finish.Sub(s.Start()) > ts.opts.SlowThreshold:
+		reason = "slow"
+	}
+	pending.keep = reason != ""
+	spans := pending.spans
+	ts.remove(pending)
+	ts.mu.Unlock()
+
+	if !pending.keep {
+		mon.Counter("jaeger_tail_sampling_dropped").Inc(1)
+		pending.trace.Set(Sampled, false)
+		return
+	}
+	mon.Counter("jaeger_tail_sampling_kept").Inc(1)
+
+	pending.trace.Set(Sampled, true)
+	pending.trace.Set(samplingKey{}, rootSamplingTags{
+		spanID: s.Id(),
+		tags:   samplerTags(samplerTypeTail, reason),
+	})
+
+	if js := ts.srv.newJaegerSpan(s, spanErr, panicked, finish); js != nil {
+		spans = append(spans, js)
+	}
+	ts.collect(pending.trace, spans)
+}
+
+// buffer adds the span to the pending trace and evicts the oldest traces if
+// the limits are exceeded. ts.mu must be held.
+func (ts *tailSampler) buffer(pending *pendingTrace, js *jaeger.Span) {
+	size := estimateSpanMemory(js)
+	pending.spans = append(pending.spans, js)
+	pending.bytes += size
+	ts.spans++
+	ts.bytes += size
+
+	for ts.spans > ts.opts.MaxPendingSpans || ts.bytes > ts.opts.MaxPendingBytes {
+		oldest := ts.pending.Front()
+		if oldest == nil {
+			break
+		}
+		mon.Counter("jaeger_tail_sampling_evicted").Inc(1)
+		ts.remove(oldest.Value.(*pendingTrace))
+	}
+}
+
+// remove removes the trace from the pending list. ts.mu must be held.
+func (ts *tailSampler) remove(pending *pendingTrace) {
+	ts.pending.Remove(pending.elem)
+	pending.elem = nil
+	ts.spans -= len(pending.spans)
+	ts.bytes -= pending.bytes
+	pending.spans = nil
+	pending.bytes = 0
+}
+
+func (ts *tailSampler) collect(trace *monkit.Trace, spans []*jaeger.Span) {
+	traceHost, _ := trace.Get(TraceHost).(string)
+	collector := ts.srv.getCollector(traceHost)
+	for _, js := range spans {
+		collector.Collect(js)
+	}
+}
+
+// estimateSpanMemory roughly estimates the memory used by the span.
+func estimateSpanMemory(js *jaeger.Span) int {
+	size := spanMemoryOverhead + len(js.OperationName)
+	for _, tag := range js.Tags {
+		size += estimateTagMemory(tag)
+	}
+	for _, log := range js.Logs {
+		size += spanMemoryOverhead
+		for _, field := range log.Fields {
+			size += estimateTagMemory(field)
+		}
+	}
+	return size
+}
+
+func estimateTagMemory(tag *jaeger.Tag) int {
+	size := tagMemoryOverhead + len(tag.Key) + len(tag.VBinary)
+	if tag.VStr != nil {
+		size += len(*tag.VStr)
+	}
+	return size
+}
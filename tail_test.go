@@ -0,0 +1,199 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func runTrace(ctx context.Context, scope *monkit.Scope, rootErr, childErr error, sleep time.Duration) *monkit.Trace {
+	var trace *monkit.Trace
+	func() {
+		err := rootErr
+		defer scope.FuncNamed("root").Task(&ctx)(&err)
+		trace = monkit.SpanFromCtx(ctx).Trace()
+
+		func() {
+			err := childErr
+			ctx := ctx
+			defer scope.FuncNamed("child").Task(&ctx)(&err)
+		}()
+		time.Sleep(sleep)
+	}()
+	return trace
+}
+
+func TestTailSampling(t *testing.T) {
+	ctx := context.Background()
+	r := monkit.NewRegistry()
+	scope := r.Package()
+	collector := &memoryCollector{}
+
+	unregister := RegisterJaeger(r, collector, Options{
+		Fraction: 0,
+		TailSampling: &TailSamplingOptions{
+			SlowThreshold: 50 * time.Millisecond,
+		},
+	})
+	defer unregister()
+
+	// fast and successful traces are dropped.
+	trace := runTrace(ctx, scope, nil, nil, 0)
+	require.Empty(t, collector.Spans())
+	require.Equal(t, false, trace.Get(Sampled))
+
+	// errored roots are kept.
+	trace = runTrace(ctx, scope, errors.New("failure"), nil, 0)
+	spans := collector.Spans()
+	require.Len(t, spans, 2)
+	require.Equal(t, true, trace.Get(Sampled))
+	tag, ok := findTag(SamplerParamTagKey, spans[1])
+	require.True(t, ok)
+	require.Equal(t, "errored", tag.GetVStr())
+
+	// errored children keep the trace too.
+	collector.spans = nil
+	runTrace(ctx, scope, nil, errors.New("failure"), 0)
+	require.Len(t, collector.Spans(), 2)
+
+	// canceled spans are not considered errors.
+	collector.spans = nil
+	runTrace(ctx, scope, context.Canceled, nil, 0)
+	require.Empty(t, collector.Spans())
+
+	// slow traces are kept.
+	runTrace(ctx, scope, nil, nil, 100*time.Millisecond)
+	spans = collector.Spans()
+	require.Len(t, spans, 2)
+	tag, ok = findTag(SamplerParamTagKey, spans[1])
+	require.True(t, ok)
+	require.Equal(t, "slow", tag.GetVStr())
+}
+
+func TestTailSamplingDefersDownstream(t *testing.T) {
+	ctx := context.Background()
+	r := monkit.NewRegistry()
+	collector := &memoryCollector{}
+
+	unregister := RegisterJaeger(r, collector, Options{
+		Fraction:     0,
+		TailSampling: &TailSamplingOptions{},
+	})
+	defer unregister()
+
+	var trace *monkit.Trace
+	func() {
+		err := errors.New("failure")
+		ctx := ctx
+		defer r.Package().FuncNamed("root").Task(&ctx)(&err)
+		trace = monkit.SpanFromCtx(ctx).Trace()
+
+		// while the trace is pending, downstream services defer the decision
+		// instead of dropping their spans.
+		remoteInfo := InjectRemoteInfo(ctx)
+		require.Equal(t, "true", remoteInfo[SamplingDeferred])
+		remote, _ := RemoteTraceHandler(remoteInfo)
+		require.NotNil(t, remote)
+		require.Nil(t, remote.Get(Sampled))
+
+		header := MapCarrier{}
+		require.True(t, B3Propagator{}.Inject(ctx, header))
+		require.NotContains(t, header, B3SampledHeader)
+	}()
+
+	require.Len(t, collector.Spans(), 1)
+	require.Equal(t, true, trace.Get(Sampled))
+
+	// the decision is propagated once it's made.
+	withRemoteSpan(trace, 1, func(ctx context.Context, span *monkit.Span) {
+		remoteInfo := InjectRemoteInfo(ctx)
+		require.Equal(t, "true", remoteInfo[Sampled])
+		require.NotContains(t, remoteInfo, SamplingDeferred)
+	})
+}
+
+func TestTailSamplingRemoteNotSampled(t *testing.T) {
+	ctx := context.Background()
+	r := monkit.NewRegistry()
+	collector := &memoryCollector{}
+
+	unregister := RegisterJaeger(r, collector, Options{
+		Fraction:     1,
+		TailSampling: &TailSamplingOptions{},
+	})
+	defer unregister()
+
+	trace := monkit.NewTrace(monkit.NewId())
+	trace.Set(Sampled, false)
+
+	func() {
+		err := errors.New("failure")
+		defer r.Package().FuncNamed("remote").RemoteTrace(&ctx, monkit.NewId(), trace)(&err)
+	}()
+
+	spans := collector.Spans()
+	require.Len(t, spans, 1)
+	require.Equal(t, true, trace.Get(Sampled))
+}
+
+func TestTailSamplingEviction(t *testing.T) {
+	ctx := context.Background()
+	r := monkit.NewRegistry()
+	scope := r.Package()
+	collector := &memoryCollector{}
+
+	unregister := RegisterJaeger(r, collector, Options{
+		Fraction: 0,
+		TailSampling: &TailSamplingOptions{
+			MaxPendingSpans: 2,
+		},
+	})
+	defer unregister()
+
+	childDone := make(chan struct{})
+	finishFirst := make(chan struct{})
+	firstDone := make(chan struct{})
+	go func() {
+		defer close(firstDone)
+		err := errors.New("failure")
+		ctx := ctx
+		defer scope.FuncNamed("first").Task(&ctx)(&err)
+		func() {
+			ctx := ctx
+			defer scope.FuncNamed("child").Task(&ctx)(nil)
+		}()
+		close(childDone)
+		<-finishFirst
+	}()
+
+	// wait for the child of the first trace to be buffered.
+	<-childDone
+
+	// the second trace exceeds the limit and evicts the first one.
+	func() {
+		err := errors.New("failure")
+		defer scope.FuncNamed("second").Task(&ctx)(&err)
+		for i := 0; i < 2; i++ {
+			func() {
+				ctx := ctx
+				defer scope.FuncNamed("child").Task(&ctx)(nil)
+			}()
+		}
+	}()
+
+	close(finishFirst)
+	<-firstDone
+
+	spans := collector.Spans()
+	require.Len(t, spans, 3)
+	for _, span := range spans {
+		require.NotContains(t, span.OperationName, "first")
+	}
+}
@@ -5,10 +5,13 @@ package jaeger
 
 import (
 	"context"
+	"errors"
+	"math"
 	"math/rand"
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/apache/thrift/lib/go/thrift"
@@ -39,11 +42,25 @@ const (
 	// defaultFlushInterval is the default interval to send data on ticker.
 	defaultFlushInterval = 15 * time.Second
 
+	// clientStatsFieldOverhead is the size of the field header of the client
+	// stats in a batch.
+	clientStatsFieldOverhead = 3
+
+	// compressedBatchFactor is how many times larger than the packet size
+	// the thrift encoded spans of a batch may get when requests are
+	// compressed. Batches which turn out too large after compression are
+	// split.
+	compressedBatchFactor = 4
+
 	// estimateSpanSize is the estimation size of a span we pre-allocate for pricise span size calculation.
 	estimateSpanSize = 600
 
-	udpTransportType  transportType = 1
-	httpTransportType transportType = 2
+	udpTransportType       transportType = 1
+	httpTransportType      transportType = 2
+	collectorTransportType transportType = 3
+	otlpTransportType      transportType = 4
+	zipkinTransportType    transportType = 5
+	fileTransportType      transportType = 6
 )
 
 // ThriftCollector matches the TraceCollector interface, but sends serialized
@@ -51,8 +68,9 @@ const (
 // RedirectPackets for the UDP server-side code.
 type ThriftCollector struct {
 	mu               sync.Mutex
-	spansToSend      []*jaeger.Span // the spans waiting to be send to the agent
-	currentSpanBytes int            // the current bytes used by spans when they are encoded into thrift buffer
+	spansToSend      []*jaeger.Span  // the spans waiting to be send to the agent
+	currentSpanBytes int             // the current bytes used by spans when they are encoded into thrift buffer
+	pendingBatches   []*jaeger.Batch // the full batches waiting to be sent together to a BatchesTransport
 
 	log           *zap.Logger
 	ch            chan *jaeger.Span
@@ -60,12 +78,29 @@ type ThriftCollector struct {
 	process       *jaeger.Process // the information of which process is sending the spans
 
 	maxSpanBytes     int                   // the max bytes spans can take up to make sure we don't exceed maxPacketSize
+	maxBatchBytes    int                   // the max bytes spans of a batch can take up before it's sent, larger than maxSpanBytes when compressed
 	maxPacketSize    int                   // the max number of bytes this instance of UDPCollector allows for a single UDP packet
 	spanSizeBuffer   *thrift.TMemoryBuffer // spanSizeBuffer helps us calculate the size of the span when thrift-encoded
 	spanSizeProtocol thrift.TProtocol
 	batchSeqNo       int64
 	agentAddr        string
 	transportType    transportType
+
+	// the client side span losses since the collector was created, which are
+	// reported with every batch.
+	fullQueueDroppedSpans atomic.Int64
+	tooLargeDroppedSpans  atomic.Int64
+	failedToEmitSpans     atomic.Int64
+
+	httpOptions  HTTPTransportOptions
+	otlpEncoding OTLPEncoding
+	fileOptions  FileTransportOptions
+
+	// the batches waiting to be retried.
+	retryPolicy     RetryPolicy
+	retries         []*retryBatch
+	retryBytes      int
+	retryGeneration atomic.Int64 // changes whenever a batch is queued
 }
 
 // NewUDPCollector creates a UDPCollector that sends packets to jaeger agent, unless (!) you use different protocol in agentAddr.
@@ -75,17 +110,32 @@ func NewUDPCollector(log *zap.Logger, agentAddr string, serviceName string, tags
 }
 
 // NewThriftCollector creates a UDPCollector that sends packets to jaeger agent.
+// Addresses with a CollectorSchemePrefix scheme send to a jaeger collector
+// instead, ones with an OTLPSchemePrefix scheme to an OpenTelemetry
+// collector, ones with a ZipkinSchemePrefix scheme to a Zipkin server, and
+// file URLs to local files, see OpenFileTransport. Use DetectProcessTags to
+// add tags describing the process to tags.
 func NewThriftCollector(log *zap.Logger, agentAddr string, serviceName string, tags []Tag, packetSize, queueSize int, flushInterval time.Duration) (
 	*ThriftCollector, error) {
 
 	tt := udpTransportType
 	parsedURL, err := url.Parse(agentAddr)
-	if err == nil && strings.Contains(parsedURL.Scheme, "http") {
+	switch {
+	case err != nil:
+	case strings.HasPrefix(parsedURL.Scheme, CollectorSchemePrefix):
+		tt = collectorTransportType
+	case strings.HasPrefix(parsedURL.Scheme, OTLPSchemePrefix):
+		tt = otlpTransportType
+	case strings.HasPrefix(parsedURL.Scheme, ZipkinSchemePrefix):
+		tt = zipkinTransportType
+	case parsedURL.Scheme == FileScheme:
+		tt = fileTransportType
+	case strings.Contains(parsedURL.Scheme, "http"):
 		tt = httpTransportType
 	}
 
 	if packetSize == 0 {
-		if tt == httpTransportType {
+		if tt != udpTransportType {
 			packetSize = maxPacketSizeHTTP
 		} else {
 			packetSize = maxPacketSizeUDP
@@ -101,7 +151,7 @@ func NewThriftCollector(log *zap.Logger, agentAddr string, serviceName string, t
 	}
 
 	var protocolFactory thrift.TProtocolFactory
-	if tt == httpTransportType {
+	if tt != udpTransportType {
 		protocolFactory = thrift.NewTBinaryProtocolFactoryConf(nil)
 	} else {
 		protocolFactory = thrift.NewTCompactProtocolFactoryConf(nil)
@@ -129,47 +179,169 @@ func NewThriftCollector(log *zap.Logger, agentAddr string, serviceName string, t
 		return nil, errs.Wrap(err)
 	}
 
+	// reserve room for the largest possible client stats.
+	statsByteSize, err := calculateThriftSize(&jaeger.ClientStats{
+		FullQueueDroppedSpans: math.MaxInt64,
+		TooLargeDroppedSpans:  math.MaxInt64,
+		FailedToEmitSpans:     math.MaxInt64,
+	}, spanSizeBuffer, spanSizeProtocol)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	statsByteSize += clientStatsFieldOverhead
+
+	maxSpanBytes := packetSize - emitBatchOverhead - processByteSize - statsByteSize
+
 	return &ThriftCollector{
 		log:              log.Named("tracing collector"),
 		ch:               make(chan *jaeger.Span, queueSize),
 		flushInterval:    flushInterval,
-		maxSpanBytes:     packetSize - emitBatchOverhead - processByteSize,
+		maxSpanBytes:     maxSpanBytes,
+		maxBatchBytes:    maxSpanBytes,
 		spanSizeBuffer:   spanSizeBuffer,
 		spanSizeProtocol: spanSizeProtocol,
 		maxPacketSize:    packetSize,
 		process:          jaegerProcess,
 		agentAddr:        agentAddr,
 		transportType:    tt,
+		retryPolicy:      RetryPolicy{}.withDefaults(flushInterval),
 	}, nil
 }
 
-// Run reads spans off the queue and appends them to the buffer. When the
-// buffer fills up, it flushes. It also flushes on a jittered interval.
-func (c *ThriftCollector) Run(ctx context.Context) {
-	c.log.Debug("started")
-	defer c.log.Debug("stopped")
+// SetHTTPTransportOptions configures the transport used for http, https, OTLP
+// and Zipkin addresses. It must be called before Run.
+//
+// With compression, the packet size limits the compressed requests instead of
+// the thrift encoded spans, so that more spans are sent per request.
+func (c *ThriftCollector) SetHTTPTransportOptions(opts HTTPTransportOptions) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxBatchBytes = c.maxSpanBytes
+	httpBased := c.transportType == httpTransportType || c.transportType == otlpTransportType ||
+		c.transportType == zipkinTransportType
+	if httpBased && opts.Compression != HTTPCompressionNone {
+		if opts.MaxRequestSize == 0 {
+			opts.MaxRequestSize = c.maxPacketSize
+		}
+		c.maxBatchBytes = c.maxSpanBytes * compressedBatchFactor
+	}
+	c.httpOptions = opts
+}
 
+// SetOTLPEncoding configures the encoding of the requests to OTLP addresses.
+// It must be called before Run.
+func (c *ThriftCollector) SetOTLPEncoding(encoding OTLPEncoding) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.otlpEncoding = encoding
+}
+
+// SetFileTransportOptions configures the transport used for file addresses.
+// It must be called before Run.
+func (c *ThriftCollector) SetFileTransportOptions(opts FileTransportOptions) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fileOptions = opts
+}
+
+// SetRetryPolicy configures how failed batches are retried. It must be called
+// before Run.
+func (c *ThriftCollector) SetRetryPolicy(policy RetryPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.retryPolicy = policy.withDefaults(c.flushInterval)
+}
+
+// OpenTransport opens the transport for the address of the collector. Run
+// opens its own, so this is only needed to send batches directly, e.g. to
+// replay them with ReplayFile.
+func (c *ThriftCollector) OpenTransport(ctx context.Context) (Transport, error) {
 	var tp Transport
 	var err error
 	switch c.transportType {
 	case httpTransportType:
-		tp, err = OpenHTTPTransport(ctx, c.log, c.agentAddr)
-		if err != nil {
-			return
-		}
+		var httpTransport *HTTPTransport
+		httpTransport, err = OpenHTTPTransportWithOptions(ctx, c.log, c.agentAddr, c.httpOptions)
+		tp = httpTransport
+	case otlpTransportType:
+		var otlpTransport *OTLPTransport
+		otlpTransport, err = OpenOTLPTransport(ctx, c.log, c.agentAddr, c.otlpEncoding, c.httpOptions)
+		tp = otlpTransport
+	case zipkinTransportType:
+		var zipkinTransport *ZipkinTransport
+		zipkinTransport, err = OpenZipkinTransport(ctx, c.log, c.agentAddr, c.httpOptions)
+		tp = zipkinTransport
+	case collectorTransportType:
+		var collectorTransport *CollectorTransport
+		collectorTransport, err = OpenCollectorTransport(ctx, c.log, c.agentAddr, CollectorTransportOptions{})
+		tp = collectorTransport
+	case fileTransportType:
+		var fileTransport *FileTransport
+		fileTransport, err = OpenFileTransport(ctx, c.log, c.agentAddr, c.fileOptions)
+		tp = fileTransport
 	case udpTransportType:
-		tp, err = OpenUDPTransport(ctx, c.log, c.agentAddr, c.maxPacketSize)
-		if err != nil {
-			return
-		}
+		var udpTransport *UDPTransport
+		udpTransport, err = OpenUDPTransport(ctx, c.log, c.agentAddr, c.maxPacketSize)
+		tp = udpTransport
 	default:
-		panic("Unsupported transport type: ")
+		return nil, errs.New("unsupported transport type %d", c.transportType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tp, nil
+}
+
+// Run reads spans off the queue and appends them to the buffer. When the
+// buffer fills up, it flushes. It also flushes on a jittered interval.
+func (c *ThriftCollector) Run(ctx context.Context) {
+	c.log.Debug("started")
+	defer c.log.Debug("stopped")
+
+	tp, err := c.OpenTransport(ctx)
+	if err != nil {
+		c.log.Debug("failed to open transport", zap.Error(err))
+		return
 	}
 	defer tp.Close()
 
 	ticker := time.NewTicker(jitter(c.flushInterval))
 	defer ticker.Stop()
 
+	// the retry timer is armed for the earliest queued batch. the queue is
+	// only checked when it changed since the timer was armed.
+	retryTimer := time.NewTimer(time.Hour)
+	retryTimer.Stop()
+	defer retryTimer.Stop()
+	var retryAt time.Time
+	var retryGeneration int64
+	scheduleRetry := func() {
+		generation := c.retryGeneration.Load()
+		if generation == retryGeneration && !retryAt.IsZero() {
+			return
+		}
+		next, ok := c.nextRetry()
+		if !ok {
+			return
+		}
+		retryGeneration = generation
+		if !retryAt.IsZero() && !next.Before(retryAt) {
+			return
+		}
+		if !retryTimer.Stop() {
+			select {
+			case <-retryTimer.C:
+			default:
+			}
+		}
+		retryAt = next
+		retryTimer.Reset(time.Until(next))
+	}
+
 	for {
 		select {
 		case s := <-c.ch:
@@ -178,10 +350,18 @@ func (c *ThriftCollector) Run(ctx context.Context) {
 				mon.Counter("jaeger_span_handling_failure").Inc(1)
 				c.log.Debug("failed to handle span", zap.Error(err))
 			}
+			scheduleRetry()
+		case <-retryTimer.C:
+			retryAt = time.Time{}
+			if err := c.Retry(ctx, tp, time.Now()); err != nil {
+				c.log.Debug("failed to retry", zap.Error(err))
+			}
+			scheduleRetry()
 		case <-ticker.C:
 			if err := c.Send(ctx, tp); err != nil {
 				c.log.Debug("failed to send on ticker", zap.Error(err))
 			}
+			scheduleRetry()
 			ticker.Reset(jitter(c.flushInterval))
 			// clear ticker
 			select {
@@ -200,9 +380,10 @@ func (c *ThriftCollector) Run(ctx context.Context) {
 					c.log.Debug("failed to handle span", zap.Error(err))
 				}
 			}
-			if err := c.send(ctxWithoutCancel, tp); err != nil {
+			if err := c.Send(ctxWithoutCancel, tp); err != nil {
 				c.log.Debug("failed to send on close", zap.Error(err))
 			}
+			c.retryOnClose(ctxWithoutCancel, tp)
 			return
 		}
 	}
@@ -222,14 +403,23 @@ func (c *ThriftCollector) handleSpan(ctx context.Context, s *jaeger.Span, transp
 	}
 
 	if spanSize > c.maxSpanBytes {
-		mon.Counter("jaeger_span_too_large").Inc(1)
-		return errs.New("span is too large. Expected no bigger than %d, got %d", c.maxSpanBytes, spanSize)
+		shrunk, shrunkSize, err := c.shrinkSpan(s, c.maxSpanBytes)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		if shrunk == nil {
+			mon.Counter("jaeger_span_too_large").Inc(1)
+			c.tooLargeDroppedSpans.Add(1)
+			return errs.New("span is too large. Expected no bigger than %d, got %d", c.maxSpanBytes, spanSize)
+		}
+		mon.Counter("jaeger_span_shrunk").Inc(1)
+		s, spanSize = shrunk, shrunkSize
 	}
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.currentSpanBytes+spanSize > c.maxSpanBytes {
+	if c.currentSpanBytes+spanSize > c.maxBatchBytes {
 		if err := c.send(ctx, transport); err != nil {
 			return errs.Wrap(err)
 		}
@@ -246,7 +436,11 @@ func (c *ThriftCollector) Send(ctx context.Context, transport Transport) (err er
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	return c.send(ctx, transport)
+	err = c.send(ctx, transport)
+	if batchesTransport, ok := transport.(BatchesTransport); ok {
+		err = errs.Combine(err, c.sendPending(ctx, batchesTransport))
+	}
+	return err
 }
 
 func (c *ThriftCollector) send(ctx context.Context, transport Transport) (err error) {
@@ -254,16 +448,83 @@ func (c *ThriftCollector) send(ctx context.Context, transport Transport) (err er
 		return nil
 	}
 
+	defer c.resetSpanBuffer()
+
+	// transports which send several batches at once get the full batches
+	// together.
+	if batchesTransport, ok := transport.(BatchesTransport); ok {
+		c.pendingBatches = append(c.pendingBatches, c.newBatch(append([]*jaeger.Span(nil), c.spansToSend...)))
+		if len(c.pendingBatches) < maxBatchesPerSubmit {
+			return nil
+		}
+		return c.sendPending(ctx, batchesTransport)
+	}
+
+	return c.sendSpans(ctx, transport, c.spansToSend)
+}
+
+// sendPending sends the pending batches with a single call. The batches which
+// failed are queued for a retry.
+func (c *ThriftCollector) sendPending(ctx context.Context, transport BatchesTransport) error {
+	if len(c.pendingBatches) == 0 {
+		return nil
+	}
+
+	batches := c.pendingBatches
+	c.pendingBatches = nil
+
+	stats := c.clientStats()
+	for _, batch := range batches {
+		batch.Stats = stats
+	}
+
+	err := transport.SendBatches(ctx, batches)
+	for i, batchErr := range batchErrors(err, len(batches)) {
+		if batchErr != nil {
+			c.queueRetry(batches[i], 1, batchErr, time.Now())
+		}
+	}
+	return errs.Wrap(err)
+}
+
+// newBatch returns a batch of the spans with the next sequence number.
+func (c *ThriftCollector) newBatch(spans []*jaeger.Span) *jaeger.Batch {
 	c.batchSeqNo++
 	batchSeqNo := c.batchSeqNo
-	batch := &jaeger.Batch{
+	return &jaeger.Batch{
 		Process: c.process,
-		Spans:   c.spansToSend,
+		Spans:   spans,
 		SeqNo:   &batchSeqNo,
+		Stats:   c.clientStats(),
 	}
-	defer c.resetSpanBuffer()
-	err = transport.Send(ctx, batch)
-	if err != nil {
+}
+
+// sendSpans sends the spans in one batch. If the batch doesn't fit into a
+// packet, it's split in halves until the parts fit. Only spans which don't fit
+// on their own are dropped.
+func (c *ThriftCollector) sendSpans(ctx context.Context, transport Transport, spans []*jaeger.Span) error {
+	batch := c.newBatch(spans)
+
+	err := transport.Send(ctx, batch)
+	if errors.Is(err, ErrBatchTooLarge) {
+		// the batch wasn't sent, so the next one gets its sequence number,
+		// and the receiver sees no gap.
+		c.batchSeqNo--
+	}
+	switch {
+	case errors.Is(err, ErrBatchTooLarge) && len(spans) > 1:
+		mon.Counter("jaeger_batch_split").Inc(1)
+		half := len(spans) / 2
+		return errs.Combine(
+			c.sendSpans(ctx, transport, spans[:half]),
+			c.sendSpans(ctx, transport, spans[half:]),
+		)
+	case errors.Is(err, ErrBatchTooLarge):
+		mon.Counter("jaeger_span_too_large").Inc(1)
+		c.tooLargeDroppedSpans.Add(1)
+		return errs.Wrap(err)
+	case err != nil:
+		c.queueRetry(batch, 1, err, time.Now())
 		return errs.Wrap(err)
 	}
 
@@ -277,6 +538,16 @@ func (c *ThriftCollector) Collect(span *jaeger.Span) {
 	case c.ch <- span:
 	default:
 		mon.Counter("jaeger_buffer_full").Inc(1)
+		c.fullQueueDroppedSpans.Add(1)
+	}
+}
+
+// clientStats returns the client side span losses so far.
+func (c *ThriftCollector) clientStats() *jaeger.ClientStats {
+	return &jaeger.ClientStats{
+		FullQueueDroppedSpans: c.fullQueueDroppedSpans.Load(),
+		TooLargeDroppedSpans:  c.tooLargeDroppedSpans.Load(),
+		FailedToEmitSpans:     c.failedToEmitSpans.Load(),
 	}
 }
 
@@ -0,0 +1,121 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap/zaptest"
+
+	"storj.io/monkit-jaeger/gen-go/jaeger"
+)
+
+// recordingTransport records the batches it sends, or fails if fail is set.
+// Batches with more than maxSpans spans, or with a span named "huge", are
+// too large.
+type recordingTransport struct {
+	fail     bool
+	maxSpans int
+	batches  []*jaeger.Batch
+}
+
+func (tr *recordingTransport) Send(ctx context.Context, batch *jaeger.Batch) error {
+	if tr.fail {
+		return errs.New("send failed")
+	}
+	if tr.maxSpans > 0 && len(batch.Spans) > tr.maxSpans {
+		return ErrBatchTooLarge
+	}
+	for _, span := range batch.Spans {
+		if span.OperationName == "huge" {
+			return ErrBatchTooLarge
+		}
+	}
+
+	// the collector reuses the span slice.
+	copied := *batch
+	copied.Spans = append([]*jaeger.Span(nil), batch.Spans...)
+	tr.batches = append(tr.batches, &copied)
+	return nil
+}
+
+func (tr *recordingTransport) Close() {}
+
+func newTestSpan(operationName string) *jaeger.Span {
+	return &jaeger.Span{
+		TraceIdLow:    monkit.NewId(),
+		SpanId:        monkit.NewId(),
+		OperationName: operationName,
+		StartTime:     time.Now().UnixNano() / 1000,
+		Duration:      time.Second.Microseconds(),
+	}
+}
+
+func TestThriftCollectorClientStats(t *testing.T) {
+	ctx := context.Background()
+	collector, err := NewThriftCollector(zaptest.NewLogger(t), "localhost:6831", "test", nil, 0, 1, time.Hour)
+	require.NoError(t, err)
+	collector.SetRetryPolicy(RetryPolicy{Attempts: 1})
+
+	// the queue holds a single span.
+	collector.Collect(newTestSpan("queued"))
+	collector.Collect(newTestSpan("dropped"))
+	<-collector.ch
+
+	transport := &recordingTransport{}
+	require.Error(t, collector.handleSpan(ctx, newTestSpan(strings.Repeat("x", maxPacketSizeUDP)), transport))
+
+	transport.fail = true
+	require.NoError(t, collector.handleSpan(ctx, newTestSpan("failed"), transport))
+	require.NoError(t, collector.handleSpan(ctx, newTestSpan("failed"), transport))
+	require.Error(t, collector.Send(ctx, transport))
+
+	transport.fail = false
+	require.NoError(t, collector.handleSpan(ctx, newTestSpan("sent"), transport))
+	require.NoError(t, collector.Send(ctx, transport))
+
+	require.Len(t, transport.batches, 1)
+	require.Equal(t, &jaeger.ClientStats{
+		FullQueueDroppedSpans: 1,
+		TooLargeDroppedSpans:  1,
+		FailedToEmitSpans:     2,
+	}, transport.batches[0].Stats)
+}
+
+func TestThriftCollectorSplitsBatches(t *testing.T) {
+	ctx := context.Background()
+	collector, err := NewThriftCollector(zaptest.NewLogger(t), "localhost:6831", "test", nil, maxPacketSizeHTTP, 0, time.Hour)
+	require.NoError(t, err)
+
+	transport := &recordingTransport{maxSpans: 2}
+	for i := 0; i < 7; i++ {
+		name := "span"
+		if i == 3 {
+			name = "huge"
+		}
+		require.NoError(t, collector.handleSpan(ctx, newTestSpan(name), transport))
+	}
+	require.ErrorIs(t, collector.Send(ctx, transport), ErrBatchTooLarge)
+
+	// the sent batches have consecutive sequence numbers.
+	var sent int
+	for i, batch := range transport.batches {
+		require.LessOrEqual(t, len(batch.Spans), 2)
+		require.Equal(t, collector.process, batch.Process)
+		require.Equal(t, int64(i+1), *batch.SeqNo)
+		for _, span := range batch.Spans {
+			require.Equal(t, "span", span.OperationName)
+		}
+		sent += len(batch.Spans)
+	}
+	require.Equal(t, 6, sent)
+	require.Equal(t, int64(1), collector.clientStats().TooLargeDroppedSpans)
+	require.Zero(t, collector.clientStats().FailedToEmitSpans)
+}
@@ -4,34 +4,123 @@
 package jaeger
 
 import (
+	"context"
+	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/spacemonkeygo/monkit/v3"
 )
 
 // RemoteTraceHandler returns a new trace and its root span id based on remote trace information.
-func RemoteTraceHandler(remoteInfo map[string]string) (trace *monkit.Trace, parentID int64) {
-	parentID, err := strconv.ParseInt(remoteInfo[ParentID], 10, 64)
+// By default, the remote information is read from the TraceID, TraceIDHigh, ParentID, Sampled
+// and TraceHost keys. If propagators are given, they are tried in order instead.
+func RemoteTraceHandler(remoteInfo map[string]string, propagators ...Propagator) (trace *monkit.Trace, parentID int64) {
+	if len(propagators) > 0 {
+		return CompositePropagator(propagators).Extract(MapCarrier(remoteInfo))
+	}
+	return RemoteInfoPropagator{}.Extract(MapCarrier(remoteInfo))
+}
+
+// InjectRemoteInfo returns the remote trace information of the span in ctx,
+// which can be turned back into a trace with RemoteTraceHandler. It returns
+// nil if ctx has no span.
+func InjectRemoteInfo(ctx context.Context) map[string]string {
+	remoteInfo := MapCarrier{}
+	if !(RemoteInfoPropagator{}).Inject(ctx, remoteInfo) {
+		return nil
+	}
+	return remoteInfo
+}
+
+// InjectRemoteInfoHeader writes the remote trace information of the span in
+// ctx into header. It returns false if ctx has no span.
+func InjectRemoteInfoHeader(ctx context.Context, header http.Header) bool {
+	return RemoteInfoPropagator{}.Inject(ctx, HeaderCarrier(header))
+}
+
+// RemoteInfoPropagator propagates traces with the TraceID, TraceIDHigh,
+// ParentID, Sampled and TraceHost keys. TraceIDHigh is only set for 128-bit
+// trace ids, and SamplingDeferred for traces without a sampling decision.
+// Baggage items are carried in keys with the BaggagePrefix.
+type RemoteInfoPropagator struct{}
+
+var _ Propagator = RemoteInfoPropagator{}
+
+// Inject implements Propagator.
+func (RemoteInfoPropagator) Inject(ctx context.Context, carrier Carrier) bool {
+	span := monkit.SpanFromCtx(ctx)
+	if span == nil {
+		return false
+	}
+	trace := span.Trace()
+
+	sampled, decided := trace.Get(Sampled).(bool)
+
+	carrier.Set(TraceID, strconv.FormatInt(trace.Id(), 10))
+	carrier.Set(ParentID, strconv.FormatInt(span.Id(), 10))
+	carrier.Set(Sampled, strconv.FormatBool(sampled))
+	if !decided {
+		// the remote side makes its own decision, or defers it too.
+		carrier.Set(SamplingDeferred, "true")
+	}
+	if high := traceIDHigh(trace); high != 0 {
+		carrier.Set(TraceIDHigh, strconv.FormatInt(high, 10))
+	}
+	if traceHost, ok := trace.Get(TraceHost).(string); ok && traceHost != "" {
+		carrier.Set(TraceHost, traceHost)
+	}
+	for key, value := range Baggage(trace) {
+		carrier.Set(BaggagePrefix+key, url.QueryEscape(value))
+	}
+	return true
+}
+
+// Extract implements Propagator.
+func (RemoteInfoPropagator) Extract(carrier Carrier) (trace *monkit.Trace, parentID int64) {
+	parentID, err := strconv.ParseInt(carrier.Get(ParentID), 10, 64)
 	if err != nil {
 		return nil, 0
 	}
 
-	traceID, err := strconv.ParseInt(remoteInfo[TraceID], 10, 64)
+	traceID, err := strconv.ParseInt(carrier.Get(TraceID), 10, 64)
 	if err != nil {
 		return nil, 0
 	}
 
-	sampled, err := strconv.ParseBool(remoteInfo[Sampled])
+	sampled, err := strconv.ParseBool(carrier.Get(Sampled))
 	if err != nil {
 		return nil, 0
 	}
 
-	if traceHost, ok := remoteInfo[TraceHost]; ok {
+	var traceIDHigh int64
+	if value := carrier.Get(TraceIDHigh); value != "" {
+		traceIDHigh, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, 0
+		}
+	}
+
+	trace = newRemoteTrace(traceIDHigh, traceID)
+	if sampled || carrier.Get(SamplingDeferred) != "true" {
+		trace.Set(Sampled, sampled)
+	}
+
+	if traceHost := carrier.Get(TraceHost); traceHost != "" {
 		trace.Set(TraceHost, traceHost)
 	}
 
-	trace = monkit.NewTrace(traceID)
-	trace.Set(Sampled, sampled)
+	for _, key := range carrier.Keys() {
+		if !strings.HasPrefix(strings.ToLower(key), BaggagePrefix) {
+			continue
+		}
+		value, err := url.QueryUnescape(carrier.Get(key))
+		if err != nil {
+			continue
+		}
+		SetBaggageItem(trace, key[len(BaggagePrefix):], value)
+	}
 
 	return trace, parentID
 }
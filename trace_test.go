@@ -0,0 +1,79 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteTraceHandler(t *testing.T) {
+	trace, parentID := RemoteTraceHandler(map[string]string{
+		TraceID:   "123",
+		ParentID:  "456",
+		Sampled:   "true",
+		TraceHost: "jaeger.example.test:6831",
+	})
+	require.NotNil(t, trace)
+	require.Equal(t, int64(123), trace.Id())
+	require.Equal(t, int64(456), parentID)
+	require.Equal(t, true, trace.Get(Sampled))
+	require.Equal(t, "jaeger.example.test:6831", trace.Get(TraceHost))
+
+	for _, invalid := range []map[string]string{
+		nil,
+		{TraceID: "123", Sampled: "true"},
+		{ParentID: "456", Sampled: "true"},
+		{TraceID: "123", ParentID: "456"},
+		{TraceID: "x", ParentID: "456", Sampled: "true"},
+		{TraceID: "123", TraceIDHigh: "x", ParentID: "456", Sampled: "true"},
+	} {
+		trace, _ := RemoteTraceHandler(invalid)
+		require.Nil(t, trace)
+	}
+}
+
+func TestInjectRemoteInfo(t *testing.T) {
+	require.Nil(t, InjectRemoteInfo(context.Background()))
+	require.False(t, InjectRemoteInfoHeader(context.Background(), http.Header{}))
+
+	for _, sampled := range []bool{true, false} {
+		trace := monkit.NewTrace(monkit.NewId())
+		trace.Set(Sampled, sampled)
+		trace.Set(TraceHost, "jaeger.example.test:6831")
+
+		withRemoteSpan(trace, 1, func(ctx context.Context, span *monkit.Span) {
+			remoteInfo := InjectRemoteInfo(ctx)
+			require.Len(t, remoteInfo, 4)
+
+			extracted, parentID := RemoteTraceHandler(remoteInfo)
+			require.NotNil(t, extracted)
+			require.Equal(t, trace.Id(), extracted.Id())
+			require.Equal(t, span.Id(), parentID)
+			require.Equal(t, sampled, extracted.Get(Sampled))
+			require.Equal(t, "jaeger.example.test:6831", extracted.Get(TraceHost))
+
+			header := http.Header{}
+			require.True(t, InjectRemoteInfoHeader(ctx, header))
+
+			extracted, parentID = RemoteInfoPropagator{}.Extract(HeaderCarrier(header))
+			require.NotNil(t, extracted)
+			require.Equal(t, trace.Id(), extracted.Id())
+			require.Equal(t, span.Id(), parentID)
+			require.Equal(t, sampled, extracted.Get(Sampled))
+			require.Equal(t, "jaeger.example.test:6831", extracted.Get(TraceHost))
+		})
+	}
+
+	// the trace host is optional.
+	withRemoteSpan(monkit.NewTrace(monkit.NewId()), 1, func(ctx context.Context, span *monkit.Span) {
+		remoteInfo := InjectRemoteInfo(ctx)
+		require.NotContains(t, remoteInfo, TraceHost)
+		require.Equal(t, "false", remoteInfo[Sampled])
+	})
+}
@@ -5,6 +5,7 @@ package jaeger
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 
@@ -16,6 +17,10 @@ import (
 	"storj.io/monkit-jaeger/gen-go/jaeger"
 )
 
+// ErrBatchTooLarge is returned by transports when a batch doesn't fit into a
+// single packet. The batch may fit when it's split.
+var ErrBatchTooLarge = errors.New("data does not fit within one packet")
+
 // UDPTransport sends jaeger batches via UDP.
 type UDPTransport struct {
 	thriftBuffer  *thrift.TMemoryBuffer
@@ -75,8 +80,8 @@ func (u *UDPTransport) Send(ctx context.Context, batch *jaeger.Batch) error {
 	// it probably is ok if we lose one batch of trace since these are just metrics data
 	if u.thriftBuffer.Len() > u.maxPacketSize {
 		mon.Counter("jaeger_exceeds_packet_size").Inc(1)
-		return fmt.Errorf("data does not fit within one UDP packet; size %d, max %d, spans %d",
-			u.thriftBuffer.Len(), u.maxPacketSize, len(batch.Spans))
+		return fmt.Errorf("%w; size %d, max %d, spans %d",
+			ErrBatchTooLarge, u.thriftBuffer.Len(), u.maxPacketSize, len(batch.Spans))
 	}
 
 	_, err := u.conn.Write(u.thriftBuffer.Bytes())
@@ -0,0 +1,124 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+const (
+	// TraceParentHeader is the W3C Trace Context header that carries the trace
+	// id, the parent span id and the trace flags.
+	TraceParentHeader = "traceparent"
+	// TraceStateHeader is the W3C Trace Context header that carries vendor
+	// specific trace information.
+	TraceStateHeader = "tracestate"
+
+	traceParentVersion      = "00"
+	traceParentLength       = 55
+	traceFlagSampled   byte = 0x01
+)
+
+// InjectTraceContext writes the W3C traceparent and tracestate headers for
+// the span in ctx into carrier. It returns false if ctx has no span.
+func InjectTraceContext(ctx context.Context, carrier Carrier) bool {
+	span := monkit.SpanFromCtx(ctx)
+	if span == nil {
+		return false
+	}
+	trace := span.Trace()
+
+	var flags byte
+	if sampled, _ := trace.Get(Sampled).(bool); sampled {
+		flags |= traceFlagSampled
+	}
+
+	carrier.Set(TraceParentHeader, fmt.Sprintf("%s-%016x%016x-%016x-%02x",
+		traceParentVersion, uint64(traceIDHigh(trace)), uint64(trace.Id()), uint64(span.Id()), flags))
+
+	if state, ok := trace.Get(TraceState).(string); ok && state != "" {
+		carrier.Set(TraceStateHeader, state)
+	}
+	return true
+}
+
+// ExtractTraceContext returns a new trace and its parent span id based on the
+// W3C traceparent and tracestate headers in carrier. It returns a nil trace if
+// there is no valid traceparent.
+func ExtractTraceContext(carrier Carrier) (trace *monkit.Trace, parentID int64) {
+	high, low, parentID, flags, ok := parseTraceParent(carrier.Get(TraceParentHeader))
+	if !ok {
+		return nil, 0
+	}
+
+	trace = newRemoteTrace(high, low)
+	trace.Set(Sampled, flags&traceFlagSampled != 0)
+	if state := strings.TrimSpace(carrier.Get(TraceStateHeader)); state != "" {
+		trace.Set(TraceState, state)
+	}
+
+	return trace, parentID
+}
+
+// parseTraceParent parses a traceparent header of the form
+// version-traceid-parentid-flags.
+func parseTraceParent(value string) (high, low, parentID int64, flags byte, ok bool) {
+	value = strings.TrimSpace(value)
+	if len(value) < traceParentLength {
+		return 0, 0, 0, 0, false
+	}
+
+	version := value[0:2]
+	switch {
+	case !isLowerHex(version) || version == "ff":
+		return 0, 0, 0, 0, false
+	case version == traceParentVersion && len(value) != traceParentLength:
+		return 0, 0, 0, 0, false
+	case len(value) > traceParentLength && value[traceParentLength] != '-':
+		// future versions may append fields.
+		return 0, 0, 0, 0, false
+	}
+
+	if value[2] != '-' || value[35] != '-' || value[52] != '-' {
+		return 0, 0, 0, 0, false
+	}
+
+	traceID, spanID, flagsHex := value[3:35], value[36:52], value[53:55]
+	if !isLowerHex(traceID) || !isLowerHex(spanID) || !isLowerHex(flagsHex) {
+		return 0, 0, 0, 0, false
+	}
+
+	highBits, _ := strconv.ParseUint(traceID[:16], 16, 64)
+	lowBits, _ := strconv.ParseUint(traceID[16:], 16, 64)
+	parentBits, _ := strconv.ParseUint(spanID, 16, 64)
+	flagBits, _ := strconv.ParseUint(flagsHex, 16, 8)
+
+	if highBits == 0 && lowBits == 0 || parentBits == 0 {
+		return 0, 0, 0, 0, false
+	}
+
+	return int64(highBits), int64(lowBits), int64(parentBits), byte(flagBits), true
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// traceIDHigh returns the high 64 bits of the trace id, if the trace has a
+// 128-bit id.
+func traceIDHigh(trace *monkit.Trace) int64 {
+	high, _ := trace.Get(TraceIDHigh).(int64)
+	return high
+}
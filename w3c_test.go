@@ -0,0 +1,102 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractTraceContext(t *testing.T) {
+	carrier := MapCarrier{
+		TraceParentHeader: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		TraceStateHeader:  "congo=t61rcWkgMzE",
+	}
+
+	trace, parentID := ExtractTraceContext(carrier)
+	require.NotNil(t, trace)
+	require.Equal(t, int64(0x4bf92f3577b34da6), traceIDHigh(trace))
+	require.Equal(t, int64(-0x5c316d62f1f1b8ca), trace.Id()) // 0xa3ce929d0e0e4736
+	require.Equal(t, int64(0x00f067aa0ba902b7), parentID)
+	require.Equal(t, true, trace.Get(Sampled))
+	require.Equal(t, "congo=t61rcWkgMzE", trace.Get(TraceState))
+
+	carrier[TraceParentHeader] = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"
+	trace, _ = ExtractTraceContext(carrier)
+	require.NotNil(t, trace)
+	require.Equal(t, false, trace.Get(Sampled))
+
+	// future versions may have additional fields.
+	carrier[TraceParentHeader] = "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"
+	trace, _ = ExtractTraceContext(carrier)
+	require.NotNil(t, trace)
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736_00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01",
+	} {
+		trace, _ := ExtractTraceContext(MapCarrier{TraceParentHeader: invalid})
+		require.Nil(t, trace, invalid)
+	}
+}
+
+func TestInjectTraceContext(t *testing.T) {
+	require.False(t, InjectTraceContext(context.Background(), MapCarrier{}))
+
+	trace := monkit.NewTrace(0x0102030405060708)
+	trace.Set(Sampled, true)
+	trace.Set(TraceIDHigh, int64(0x1112131415161718))
+	trace.Set(TraceState, "vendor=value")
+
+	ctx := context.Background()
+	defer monkit.NewRegistry().Package().Func().RemoteTrace(&ctx, 1, trace)(nil)
+	span := monkit.SpanFromCtx(ctx)
+
+	header := http.Header{}
+	require.True(t, InjectTraceContext(ctx, HeaderCarrier(header)))
+	require.Equal(t, "vendor=value", header.Get(TraceStateHeader))
+
+	// round trip
+	extracted, parentID := ExtractTraceContext(HeaderCarrier(header))
+	require.NotNil(t, extracted)
+	require.Equal(t, trace.Id(), extracted.Id())
+	require.Equal(t, int64(0x1112131415161718), traceIDHigh(extracted))
+	require.Equal(t, span.Id(), parentID)
+	require.Equal(t, true, extracted.Get(Sampled))
+	require.Equal(t, "vendor=value", extracted.Get(TraceState))
+}
+
+func TestTraceContextExport(t *testing.T) {
+	r := monkit.NewRegistry()
+	collector := &memoryCollector{}
+	unregister := RegisterJaeger(r, collector, Options{Fraction: 1})
+	defer unregister()
+
+	trace, parentID := ExtractTraceContext(MapCarrier{
+		TraceParentHeader: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		TraceStateHeader:  "congo=t61rcWkgMzE",
+	})
+	func() {
+		ctx := context.Background()
+		defer r.Package().Func().RemoteTrace(&ctx, parentID, trace)(nil)
+	}()
+
+	spans := collector.Spans()
+	require.Len(t, spans, 1)
+	require.Equal(t, int64(0x4bf92f3577b34da6), spans[0].TraceIdHigh)
+	require.Equal(t, trace.Id(), spans[0].TraceIdLow)
+	require.Equal(t, parentID, spans[0].ParentSpanId)
+	require.Equal(t, NewJaegerTags([]Tag{{Key: SpanKindTag, Value: SpanKindServer}}), spans[0].Tags)
+}
@@ -0,0 +1,182 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/monkit-jaeger/gen-go/jaeger"
+)
+
+// ZipkinSchemePrefix is the prefix of the address schemes which make a
+// ThriftCollector send to a Zipkin server instead of a jaeger agent, e.g.
+// "zipkin+http://zipkin:9411".
+const ZipkinSchemePrefix = "zipkin+"
+
+// ZipkinSpansPath is the path Zipkin spans are posted to if the endpoint has
+// no path.
+const ZipkinSpansPath = "/api/v2/spans"
+
+// ZipkinTransport sends spans to a Zipkin server with the Zipkin v2 JSON API.
+type ZipkinTransport struct {
+	*HTTPTransport
+}
+
+var _ Transport = &ZipkinTransport{}
+
+// OpenZipkinTransport creates a new transport which posts the spans in
+// Zipkin v2 JSON format to endpoint. endpoint may have a ZipkinSchemePrefix,
+// and defaults to ZipkinSpansPath if it has no path.
+func OpenZipkinTransport(ctx context.Context, log *zap.Logger, endpoint string, opts HTTPTransportOptions) (*ZipkinTransport, error) {
+	parsed, err := url.Parse(strings.TrimPrefix(endpoint, ZipkinSchemePrefix))
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	if parsed.Path == "" || parsed.Path == "/" {
+		parsed.Path = ZipkinSpansPath
+	}
+
+	transport, err := openHTTPTransport(log, parsed.String(), opts, "application/json", func(ctx context.Context, batch *jaeger.Batch) ([]byte, error) {
+		return json.Marshal(newZipkinSpans(batch))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ZipkinTransport{HTTPTransport: transport}, nil
+}
+
+// zipkinSpan is a span of the Zipkin v2 API, see
+// https://zipkin.io/zipkin-api/#/default/post_spans.
+type zipkinSpan struct {
+	TraceID       string             `json:"traceId"`
+	ID            string             `json:"id"`
+	ParentID      string             `json:"parentId,omitempty"`
+	Name          string             `json:"name,omitempty"`
+	Kind          string             `json:"kind,omitempty"`
+	Timestamp     int64              `json:"timestamp,omitempty"`
+	Duration      int64              `json:"duration,omitempty"`
+	Debug         bool               `json:"debug,omitempty"`
+	LocalEndpoint *zipkinEndpoint    `json:"localEndpoint,omitempty"`
+	Annotations   []zipkinAnnotation `json:"annotations,omitempty"`
+	Tags          map[string]string  `json:"tags,omitempty"`
+}
+
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName,omitempty"`
+	IPv4        string `json:"ipv4,omitempty"`
+	IPv6        string `json:"ipv6,omitempty"`
+}
+
+type zipkinAnnotation struct {
+	Timestamp int64  `json:"timestamp"`
+	Value     string `json:"value"`
+}
+
+// newZipkinSpans converts the spans of the batch. The process is the local
+// endpoint of every span.
+func newZipkinSpans(batch *jaeger.Batch) []*zipkinSpan {
+	var endpoint *zipkinEndpoint
+	if batch.Process != nil {
+		endpoint = &zipkinEndpoint{ServiceName: batch.Process.ServiceName}
+		for _, tag := range batch.Process.Tags {
+			if tag.Key != IPTagKey {
+				continue
+			}
+			if ip := tag.GetVStr(); strings.Contains(ip, ":") {
+				endpoint.IPv6 = ip
+			} else {
+				endpoint.IPv4 = ip
+			}
+		}
+	}
+
+	spans := make([]*zipkinSpan, 0, len(batch.Spans))
+	for _, span := range batch.Spans {
+		converted := newZipkinSpan(span)
+		converted.LocalEndpoint = endpoint
+		spans = append(spans, converted)
+	}
+	return spans
+}
+
+// newZipkinSpan converts the span. The span kind is taken from the span.kind
+// tag, and the logs become annotations.
+func newZipkinSpan(span *jaeger.Span) *zipkinSpan {
+	converted := &zipkinSpan{
+		TraceID:   zipkinID(span.TraceIdLow),
+		ID:        zipkinID(span.SpanId),
+		Name:      span.OperationName,
+		Timestamp: span.StartTime,
+		Duration:  span.Duration,
+		Debug:     span.Flags&flagDebug != 0,
+	}
+	if span.TraceIdHigh != 0 {
+		converted.TraceID = zipkinID(span.TraceIdHigh) + converted.TraceID
+	}
+	if span.ParentSpanId != 0 {
+		converted.ParentID = zipkinID(span.ParentSpanId)
+	}
+
+	for _, tag := range span.Tags {
+		if tag.Key == SpanKindTag {
+			converted.Kind = strings.ToUpper(tag.GetVStr())
+			continue
+		}
+		if converted.Tags == nil {
+			converted.Tags = make(map[string]string, len(span.Tags))
+		}
+		converted.Tags[tag.Key] = zipkinTagValue(tag)
+	}
+
+	for _, log := range span.Logs {
+		converted.Annotations = append(converted.Annotations, zipkinAnnotation{
+			Timestamp: log.Timestamp,
+			Value:     zipkinAnnotationValue(log.Fields),
+		})
+	}
+
+	return converted
+}
+
+// zipkinID formats an id as 16 hex digits.
+func zipkinID(id int64) string {
+	return fmt.Sprintf("%016x", uint64(id))
+}
+
+func zipkinTagValue(tag *jaeger.Tag) string {
+	switch tag.VType {
+	case jaeger.TagType_BOOL:
+		return strconv.FormatBool(tag.GetVBool())
+	case jaeger.TagType_LONG:
+		return strconv.FormatInt(tag.GetVLong(), 10)
+	case jaeger.TagType_DOUBLE:
+		return strconv.FormatFloat(tag.GetVDouble(), 'g', -1, 64)
+	case jaeger.TagType_BINARY:
+		return fmt.Sprintf("%x", tag.VBinary)
+	default:
+		return tag.GetVStr()
+	}
+}
+
+// zipkinAnnotationValue formats the fields of a log as "key=value" pairs, or
+// as the value of its only "event" field.
+func zipkinAnnotationValue(fields []*jaeger.Tag) string {
+	if len(fields) == 1 && fields[0].Key == "event" {
+		return zipkinTagValue(fields[0])
+	}
+
+	pairs := make([]string, 0, len(fields))
+	for _, field := range fields {
+		pairs = append(pairs, field.Key+"="+zipkinTagValue(field))
+	}
+	return strings.Join(pairs, " ")
+}
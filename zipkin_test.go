@@ -0,0 +1,114 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package jaeger
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"storj.io/common/testcontext"
+	"storj.io/monkit-jaeger/gen-go/jaeger"
+)
+
+func TestZipkinTransport(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	type request struct {
+		path, contentType string
+		body              []byte
+	}
+	requests := make(chan request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests <- request{path: r.URL.Path, contentType: r.Header.Get("Content-Type"), body: body}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	batch := &jaeger.Batch{
+		Process: &jaeger.Process{
+			ServiceName: "uplink",
+			Tags:        NewJaegerTags([]Tag{{Key: IPTagKey, Value: "10.0.0.1"}}),
+		},
+		Spans: []*jaeger.Span{
+			{
+				TraceIdHigh:   0x0a,
+				TraceIdLow:    0x0b,
+				SpanId:        0x0c,
+				ParentSpanId:  0x0d,
+				OperationName: "upload",
+				StartTime:     1000,
+				Duration:      500,
+				Flags:         flagSampled | flagDebug,
+				Tags: NewJaegerTags([]Tag{
+					{Key: SpanKindTag, Value: SpanKindClient},
+					{Key: "bytes", Value: 42},
+					NewErrorTag(),
+				}),
+				Logs: append(
+					newJaegerLogs(time.Unix(0, 1200000), Tag{Key: "error", Value: "disk full"}, Tag{Key: ErrorKindTag, Value: "io"}),
+					newJaegerLogs(time.Unix(0, 1300000), Tag{Key: "event", Value: "retrying"})...),
+			},
+			{
+				TraceIdLow:    -1,
+				SpanId:        0x0f,
+				OperationName: "root",
+				StartTime:     2000,
+				Duration:      1,
+			},
+		},
+	}
+
+	transport, err := OpenZipkinTransport(ctx, zaptest.NewLogger(t), ZipkinSchemePrefix+server.URL, HTTPTransportOptions{})
+	require.NoError(t, err)
+	defer transport.Close()
+	require.NoError(t, transport.Send(ctx, batch))
+
+	r := <-requests
+	require.Equal(t, ZipkinSpansPath, r.path)
+	require.Equal(t, "application/json", r.contentType)
+
+	var spans []map[string]interface{}
+	require.NoError(t, json.Unmarshal(r.body, &spans))
+	require.Len(t, spans, 2)
+
+	require.Equal(t, map[string]interface{}{
+		"traceId":   "000000000000000a000000000000000b",
+		"id":        "000000000000000c",
+		"parentId":  "000000000000000d",
+		"name":      "upload",
+		"kind":      "CLIENT",
+		"timestamp": float64(1000),
+		"duration":  float64(500),
+		"debug":     true,
+		"localEndpoint": map[string]interface{}{
+			"serviceName": "uplink",
+			"ipv4":        "10.0.0.1",
+		},
+		"annotations": []interface{}{
+			map[string]interface{}{"timestamp": float64(1200), "value": "error=disk full error.kind=io"},
+			map[string]interface{}{"timestamp": float64(1300), "value": "retrying"},
+		},
+		"tags": map[string]interface{}{
+			"bytes": "42",
+			"error": "true",
+		},
+	}, spans[0])
+
+	require.Equal(t, "ffffffffffffffff", spans[1]["traceId"])
+	require.NotContains(t, spans[1], "parentId")
+	require.NotContains(t, spans[1], "kind")
+
+	collector, err := NewThriftCollector(zaptest.NewLogger(t), ZipkinSchemePrefix+server.URL, "test", nil, 0, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, zipkinTransportType, collector.transportType)
+}